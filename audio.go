@@ -1,8 +1,16 @@
 package openai
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fabiustech/openai/audio"
 	"github.com/fabiustech/openai/models"
@@ -30,10 +38,120 @@ type AudioTranscriptionRequest struct {
 	Language *string
 }
 
+// writeForm implements formWriter.
+func (ar *AudioTranscriptionRequest) writeForm(w *multipart.Writer) error {
+	if err := w.WriteField("model", ar.Model.String()); err != nil {
+		return err
+	}
+
+	if ar.ResponseFormat != nil {
+		if err := w.WriteField("response_format", ar.ResponseFormat.String()); err != nil {
+			return err
+		}
+	}
+
+	if ar.Temperature != nil {
+		if err := w.WriteField("temperature", fmt.Sprintf("%f", *ar.Temperature)); err != nil {
+			return err
+		}
+	}
+
+	if ar.Language != nil {
+		if err := w.WriteField("language", *ar.Language); err != nil {
+			return err
+		}
+	}
+
+	var fw, err = w.CreateFormFile("file", ar.File.Name())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, ar.File)
+
+	return err
+}
+
+// responseCost implements responseCoster, pricing the duration of the audio reported by the transcript |body|.
+func (ar *AudioTranscriptionRequest) responseCost(body []byte) (float64, error) {
+	var format = audio.FormatJSON
+	if ar.ResponseFormat != nil {
+		format = *ar.ResponseFormat
+	}
+
+	var d, err = transcriptDuration(format, body)
+	if err != nil {
+		return 0, err
+	}
+
+	return AudioCost(ar.Model, d)
+}
+
+// transcriptDuration returns the duration of the audio transcribed by |body|, a transcript in |format|. For SRT and
+// VTT transcripts, this is the end of the last cue.
+func transcriptDuration(format audio.Format, body []byte) (time.Duration, error) {
+	switch format {
+	case audio.FormatVerboseJSON:
+		var transcript struct {
+			Duration float64 `json:"duration"`
+		}
+		if err := json.Unmarshal(body, &transcript); err != nil {
+			return 0, err
+		}
+
+		return time.Duration(transcript.Duration * float64(time.Second)), nil
+	case audio.FormatSRT, audio.FormatVTT:
+		// Cues are timed as e.g. "00:01:02,500 --> 00:01:04,000".
+		var i = bytes.LastIndex(body, []byte("-->"))
+		if i < 0 {
+			return 0, nil
+		}
+
+		var end = bytes.Fields(body[i+len("-->"):])
+		if len(end) == 0 {
+			return 0, fmt.Errorf("openai: malformed %s transcript", format)
+		}
+
+		return parseTimestamp(string(end[0]))
+	default:
+		return 0, fmt.Errorf("openai: %s transcripts don't report their duration", format)
+	}
+}
+
+// parseTimestamp parses an SRT or VTT timestamp, e.g. "01:02:03,500" or "02:03.500".
+func parseTimestamp(ts string) (time.Duration, error) {
+	var parts = strings.Split(strings.Replace(ts, ",", ".", 1), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("openai: malformed timestamp %q", ts)
+	}
+
+	var seconds, err = strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("openai: malformed timestamp %q", ts)
+	}
+
+	var d = time.Duration(seconds * float64(time.Second))
+	for i, unit := range []time.Duration{time.Minute, time.Hour}[:len(parts)-1] {
+		var n, err = strconv.Atoi(parts[len(parts)-2-i])
+		if err != nil {
+			return 0, fmt.Errorf("openai: malformed timestamp %q", ts)
+		}
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
+
 // TranscribeAudioFile creates a new audio file transcription request. File uploads are currently limited to 25 MB
 // and the following input file types are supported:mp3, mp4, mpeg, mpga, m4a, wav, and webm.
 // The returned []byte is the raw response from the API (as the response format changes depending on the contents of
-// the request).
+// the request). A Budget charges the duration of the audio reported by verbose JSON, SRT and VTT transcripts; JSON
+// and text transcripts don't report it, so are not charged.
 func (c *Client) TranscribeAudioFile(ctx context.Context, ar *AudioTranscriptionRequest) ([]byte, error) {
-	return c.postAudio(ctx, ar)
+	var b []byte
+	if err := c.postAudio(ctx, ar, &b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
 }
@@ -0,0 +1,102 @@
+package openai
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/fabiustech/openai/routes"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI Service API version used if AzureConfig.APIVersion is not set.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// AzureScope is the scope of Azure AD (Microsoft Entra ID) tokens for Azure OpenAI Service, e.g. for use with
+// azidentity credentials in an AzureConfig.TokenProvider.
+const AzureScope = "https://cognitiveservices.azure.com/.default"
+
+// AzureConfig configures a Client to call Azure OpenAI Service rather than OpenAI. See WithAzure.
+type AzureConfig struct {
+	// Endpoint is the endpoint of the Azure OpenAI resource, e.g. "https://{your-resource-name}.openai.azure.com".
+	Endpoint string
+	// APIVersion is sent as the "api-version" query parameter of every request.
+	// Defaults to DefaultAzureAPIVersion.
+	APIVersion string
+	// Deployments maps model IDs to the names of the deployments serving them. Requests for models without an entry
+	// are sent to a deployment named after the model's ID.
+	Deployments map[string]string
+	// DefaultDeployment is the deployment which requests without a model (e.g. image requests) are sent to.
+	DefaultDeployment string
+	// TokenProvider, if set, provides Azure AD (Microsoft Entra ID) tokens with the AzureScope scope, which are sent as
+	// bearer tokens instead of sending the Client's token as the "api-key" header. Tokens are cached as described by
+	// WithCredentialProvider, so a short-lived token is refreshed shortly before its Expiry.
+	TokenProvider CredentialProvider
+}
+
+// WithAzure configures the Client to call the Azure OpenAI Service described by |cfg|. Requests to model endpoints
+// (e.g. routes.ChatCompletions) are sent to "/openai/deployments/{deployment}", and the Client's token is sent as the
+// "api-key" header. If |cfg|.Endpoint cannot be parsed, the error is returned from every subsequent request made by
+// the Client.
+func WithAzure(cfg *AzureConfig) Option {
+	return func(c *Client) {
+		var u, err = url.Parse(cfg.Endpoint)
+		if err != nil {
+			if c.err == nil {
+				c.err = err
+			}
+			return
+		}
+
+		var version = cfg.APIVersion
+		if version == "" {
+			version = DefaultAzureAPIVersion
+		}
+
+		c.azure = cfg
+		if cfg.TokenProvider != nil {
+			c.azureTokens = newCredentialCache(cfg.TokenProvider)
+		}
+		c.scheme = u.Scheme
+		c.host = u.Host
+		c.base = path.Join(u.Path, "openai")
+		c.params = url.Values{"api-version": {version}}.Encode()
+	}
+}
+
+// NewAzureClient creates a new client for the Azure OpenAI Service described by |cfg|, authenticated with the API
+// key |key| (which may be empty if |cfg| has a TokenProvider). Any |opts| are applied in order, after |cfg|.
+func NewAzureClient(key string, cfg *AzureConfig, opts ...Option) *Client {
+	return NewClient(key, append([]Option{WithAzure(cfg)}, opts...)...)
+}
+
+// deploymentRoutes are the routes which Azure OpenAI Service serves per deployment.
+var deploymentRoutes = map[string]bool{
+	routes.Completions:         true,
+	routes.ChatCompletions:     true,
+	routes.Edits:               true,
+	routes.Embeddings:          true,
+	routes.ImageGenerations:    true,
+	routes.ImageEdits:          true,
+	routes.ImageVariations:     true,
+	routes.AudioTranscriptions: true,
+}
+
+// deploymentPath returns the path of |route| for a request for |model|, relative to the Client's base path.
+func (cfg *AzureConfig) deploymentPath(route, model string) (string, error) {
+	if !deploymentRoutes[route] {
+		return route, nil
+	}
+
+	var deployment = cfg.DefaultDeployment
+	if model != "" {
+		deployment = model
+		if d, ok := cfg.Deployments[model]; ok {
+			deployment = d
+		}
+	}
+	if deployment == "" {
+		return "", fmt.Errorf("openai: no Azure deployment for %s request without a model", route)
+	}
+
+	return path.Join("deployments", deployment, route), nil
+}
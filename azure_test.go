@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+)
+
+func TestAzure(t *testing.T) {
+	var got *http.Request
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	var req = &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+	}
+
+	var client = NewAzureClient(testToken, &AzureConfig{
+		Endpoint:    ts.URL,
+		Deployments: map[string]string{"gpt-4o": "my-gpt"},
+	})
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+	if got.URL.Path != "/openai/deployments/my-gpt/chat/completions" {
+		t.Errorf("unexpected path: %s", got.URL.Path)
+	}
+	if v := got.URL.Query().Get("api-version"); v != DefaultAzureAPIVersion {
+		t.Errorf("expected api-version %s, got %s", DefaultAzureAPIVersion, v)
+	}
+	if got.Header.Get("api-key") != testToken || got.Header.Get("Authorization") != "" {
+		t.Errorf("expected api-key authentication, got headers %v", got.Header)
+	}
+
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	if got.URL.Path != "/openai/models" {
+		t.Errorf("unexpected path: %s", got.URL.Path)
+	}
+
+	if _, err := client.CreateImage(context.Background(), &CreateImageRequest{Prompt: "a cat"}); err == nil {
+		t.Error("expected an error for an image request without a deployment")
+	}
+
+	client = NewAzureClient("", &AzureConfig{
+		Endpoint:          ts.URL + "/base",
+		APIVersion:        "2024-06-01",
+		DefaultDeployment: "dalle",
+		TokenProvider: CredentialProviderFunc(func(context.Context) (*Credential, error) {
+			return &Credential{Token: "aad-token", Expiry: time.Now().Add(time.Hour)}, nil
+		}),
+	})
+	if _, err := client.CreateImage(context.Background(), &CreateImageRequest{Prompt: "a cat"}); err != nil {
+		t.Fatalf("CreateImage error: %v", err)
+	}
+	if got.URL.Path != "/base/openai/deployments/dalle/images/generations" {
+		t.Errorf("unexpected path: %s", got.URL.Path)
+	}
+	if v := got.URL.Query().Get("api-version"); v != "2024-06-01" {
+		t.Errorf("expected api-version 2024-06-01, got %s", v)
+	}
+	if got.Header.Get("Authorization") != "Bearer aad-token" || got.Header.Get("api-key") != "" {
+		t.Errorf("expected bearer authentication, got headers %v", got.Header)
+	}
+
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+	if got.URL.Path != "/base/openai/deployments/gpt-4o/chat/completions" {
+		t.Errorf("unexpected path: %s", got.URL.Path)
+	}
+}
@@ -0,0 +1,333 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+)
+
+// BudgetWindow is the period over which a BudgetLimit applies.
+type BudgetWindow int
+
+const (
+	// BudgetLifetime limits usage over the lifetime of the Budget.
+	BudgetLifetime BudgetWindow = iota
+	// BudgetPerMinute limits usage per calendar minute.
+	BudgetPerMinute
+	// BudgetPerDay limits usage per UTC day.
+	BudgetPerDay
+)
+
+// String implements the fmt.Stringer interface.
+func (w BudgetWindow) String() string {
+	switch w {
+	case BudgetLifetime:
+		return "lifetime"
+	case BudgetPerMinute:
+		return "minute"
+	case BudgetPerDay:
+		return "day"
+	default:
+		return ""
+	}
+}
+
+// duration returns the length of the window, or 0 if it never resets.
+func (w BudgetWindow) duration() time.Duration {
+	switch w {
+	case BudgetPerMinute:
+		return time.Minute
+	case BudgetPerDay:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// BudgetScope specifies how a Budget attributes usage.
+type BudgetScope int
+
+const (
+	// BudgetPerClient applies the Budget's limits to all requests made by the Client.
+	BudgetPerClient BudgetScope = iota
+	// BudgetPerUser applies the Budget's limits separately to each value of the requests' User field.
+	BudgetPerUser
+	// BudgetPerKey applies the Budget's limits separately to each key set with ContextWithBudgetKey.
+	BudgetPerKey
+)
+
+// BudgetLimit limits the tokens and estimated spend within a BudgetWindow. Zero values are unlimited.
+type BudgetLimit struct {
+	Window BudgetWindow
+	// Tokens is the maximum number of tokens (prompt and completion).
+	Tokens int
+	// Dollars is the maximum estimated spend, in US dollars, priced with models.PriceOf.
+	Dollars float64
+}
+
+// Budget tracks the cumulative tokens and estimated spend of a Client's requests, and rejects requests once a limit
+// has been reached. Usage is read from the Usage of every response; streams are requested to report their Usage in a
+// final chunk, which is only sent to the caller if StreamOptions.IncludeUsage is set. Responses without a Usage (e.g.
+// from the images endpoints, or streams which fail before their final chunk) are charged the request's EstimateCost,
+// if it has one, and audio transcriptions are charged the AudioCost of the duration reported by their response (see
+// TranscribeAudioFile). Models without models.Pricing are counted towards token limits only.
+//
+// A Budget may be shared by several Clients, and must not be copied after first use.
+type Budget struct {
+	// Scope specifies how usage is attributed.
+	// Defaults to BudgetPerClient.
+	Scope BudgetScope
+	// Limits are the limits to enforce. Each is checked before every request.
+	Limits []*BudgetLimit
+	// Estimate specifies that requests are only sent if their estimated tokens, and cost (for requests with an
+	// EstimateCost method), fit within the remaining budget. Otherwise, requests are only rejected once a limit has
+	// already been reached. Either way, the estimates of requests in flight count towards the limits until their
+	// actual usage is known, so that concurrent requests cannot together overshoot them.
+	Estimate bool
+	// Warn, if set, is called for requests which exceed a limit; the requests are sent anyway. Otherwise, they are not
+	// sent and a *BudgetExceededError is returned.
+	Warn func(ctx context.Context, err *BudgetExceededError)
+
+	mu       sync.Mutex
+	accounts map[string]*budgetAccount
+	// now returns the current time. Defaults to time.Now.
+	now func() time.Time
+}
+
+// WithBudget configures the Client to enforce |b| on every request.
+func WithBudget(b *Budget) Option {
+	return func(c *Client) {
+		c.budget = b
+	}
+}
+
+type budgetKey struct{}
+
+// ContextWithBudgetKey returns a copy of |ctx| whose requests are attributed to |key| by Budgets with BudgetPerKey
+// scope.
+func ContextWithBudgetKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, budgetKey{}, key)
+}
+
+// BudgetExceededError is returned for requests which would exceed a BudgetLimit.
+type BudgetExceededError struct {
+	// Key is the user or key to which the request was attributed. Empty for BudgetPerClient.
+	Key string
+	// Limit is the limit which would be exceeded.
+	Limit *BudgetLimit
+	// Tokens and Dollars are the usage within the Limit's window when the request was made.
+	Tokens  int
+	Dollars float64
+}
+
+// Error implements the error interface.
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("openai: %s budget exceeded for %q: used %d tokens and $%.4f", e.Limit.Window, e.Key, e.Tokens,
+		e.Dollars)
+}
+
+// Spent returns the tokens and estimated spend attributed to |key| within the current |w|, including the estimates
+// of requests in flight. For BudgetPerClient, the key is "".
+func (b *Budget) Spent(key string, w BudgetWindow) (int, float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var ctr = b.account(key).counter(w, b.time())
+
+	return ctr.tokens, ctr.dollars
+}
+
+// budgetAccount holds the usage of a single key, per BudgetWindow.
+type budgetAccount struct {
+	counters [BudgetPerDay + 1]budgetCounter
+}
+
+type budgetCounter struct {
+	start   time.Time
+	tokens  int
+	dollars float64
+}
+
+// counter returns the counter for |w| at |now|, resetting it if a new window has begun.
+func (a *budgetAccount) counter(w BudgetWindow, now time.Time) *budgetCounter {
+	var ctr = &a.counters[w]
+	if d := w.duration(); d > 0 {
+		if start := now.Truncate(d); !start.Equal(ctr.start) {
+			*ctr = budgetCounter{start: start}
+		}
+	}
+
+	return ctr
+}
+
+func (b *Budget) time() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+
+	return time.Now()
+}
+
+func (b *Budget) account(key string) *budgetAccount {
+	if b.accounts == nil {
+		b.accounts = make(map[string]*budgetAccount)
+	}
+
+	var a, ok = b.accounts[key]
+	if !ok {
+		a = &budgetAccount{}
+		b.accounts[key] = a
+	}
+
+	return a
+}
+
+func (b *Budget) key(ctx context.Context, user string) string {
+	switch b.Scope {
+	case BudgetPerUser:
+		return user
+	case BudgetPerKey:
+		var key, _ = ctx.Value(budgetKey{}).(string)
+		return key
+	default:
+		return ""
+	}
+}
+
+// budgetReservation is the estimated usage of a request which has been admitted, but whose actual usage is not yet
+// known.
+type budgetReservation struct {
+	key     string
+	tokens  int
+	dollars float64
+	// starts holds the start of each window the reservation was made in, so that it is only released from the same
+	// windows.
+	starts [BudgetPerDay + 1]time.Time
+}
+
+// reserve checks a request on behalf of |key|, estimated to use |tokens| and |dollars|, against the limits. Unless it
+// would exceed one and there is no Warn func, its estimate is reserved, so that concurrent requests are checked
+// against it. The *BudgetExceededError is returned either way.
+func (b *Budget) reserve(key string, tokens int, dollars float64) (*budgetReservation, *BudgetExceededError) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var checkTokens, checkDollars = 0, 0.0
+	if b.Estimate {
+		checkTokens, checkDollars = tokens, dollars
+	}
+
+	var a = b.account(key)
+	var now = b.time()
+	var exceeded *BudgetExceededError
+	for _, l := range b.Limits {
+		var ctr = a.counter(l.Window, now)
+		if (l.Tokens > 0 && (ctr.tokens >= l.Tokens || ctr.tokens+checkTokens > l.Tokens)) ||
+			(l.Dollars > 0 && (ctr.dollars >= l.Dollars || ctr.dollars+checkDollars > l.Dollars)) {
+			exceeded = &BudgetExceededError{Key: key, Limit: l, Tokens: ctr.tokens, Dollars: ctr.dollars}
+			break
+		}
+	}
+	if exceeded != nil && b.Warn == nil {
+		return nil, exceeded
+	}
+
+	var r = &budgetReservation{key: key, tokens: tokens, dollars: dollars}
+	for w := range a.counters {
+		var ctr = a.counter(BudgetWindow(w), now)
+		ctr.tokens += tokens
+		ctr.dollars += dollars
+		r.starts[w] = ctr.start
+	}
+
+	return r, exceeded
+}
+
+// settle replaces the reservation |r| with |tokens| and |dollars| of actual usage.
+func (b *Budget) settle(r *budgetReservation, tokens int, dollars float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var a = b.account(r.key)
+	var now = b.time()
+	for w := range a.counters {
+		var ctr = a.counter(BudgetWindow(w), now)
+		if ctr.start.Equal(r.starts[w]) {
+			ctr.tokens -= r.tokens
+			ctr.dollars -= r.dollars
+		}
+		ctr.tokens += tokens
+		ctr.dollars += dollars
+	}
+}
+
+// estimator is implemented by requests whose cost can be estimated before they are sent.
+type estimator interface {
+	EstimateCost() (float64, error)
+}
+
+// responseCoster is implemented by requests whose cost is determined by their response, rather than by its Usage.
+type responseCoster interface {
+	responseCost(body []byte) (float64, error)
+}
+
+// usageReporter is implemented by streamed events which may carry a Usage.
+type usageReporter interface {
+	usage() *Usage
+}
+
+// admit checks |r|, the encoding of |payload| described by |meta|, against the Budget and reserves its estimated usage.
+// If it may be sent, exactly one of the returned funcs must be called: the first with the Usage and body of its
+// response (nil for streams, whose Usage is reported by their final chunk), or the second if no response was
+// received. admit is a no-op if |b| is nil.
+func (b *Budget) admit(ctx context.Context, payload any, r *request,
+	meta *requestMeta) (func(*Usage, []byte), func(), error) {
+	if b == nil {
+		return nil, nil, nil
+	}
+
+	var key = b.key(ctx, meta.User)
+
+	// Requests whose cost cannot be estimated (e.g. because the tokenizer is unavailable) are reserved as if free.
+	var estimate float64
+	if est, ok := payload.(estimator); ok {
+		estimate, _ = est.EstimateCost()
+	}
+
+	var res, exceeded = b.reserve(key, estimatedTokens(payload, r.body), estimate)
+	if exceeded != nil {
+		if b.Warn == nil {
+			return nil, nil, exceeded
+		}
+		b.Warn(ctx, exceeded)
+	}
+
+	var record = func(u *Usage, body []byte) {
+		if u == nil {
+			var cost float64
+			var err error
+			switch p := payload.(type) {
+			case responseCoster:
+				cost, err = p.responseCost(body)
+			case estimator:
+				cost, err = p.EstimateCost()
+			}
+			if err != nil {
+				cost = 0
+			}
+			b.settle(res, 0, cost)
+			return
+		}
+
+		var dollars float64
+		if p, ok := models.PriceOf(meta.Model); ok {
+			dollars = u.cost(p)
+		}
+		b.settle(res, u.TotalTokens, dollars)
+	}
+
+	return record, func() { b.settle(res, 0, 0) }, nil
+}
@@ -0,0 +1,333 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabiustech/openai/audio"
+	"github.com/fabiustech/openai/models"
+)
+
+func budgetTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[],` +
+				`"usage":{"prompt_tokens":60,"completion_tokens":20,"total_tokens":80}}`))
+		case "/v1/images/generations":
+			_, _ = w.Write([]byte(`{"created":1,"data":[{"url":"https://example.com/a.png"}]}`))
+		default:
+			http.Error(w, "the resource path doesn't exist", http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBudget(t *testing.T) {
+	var ts = budgetTestServer()
+	defer ts.Close()
+
+	var now = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	var b = &Budget{
+		Scope:  BudgetPerUser,
+		Limits: []*BudgetLimit{{Window: BudgetPerMinute, Tokens: 100}},
+		now:    func() time.Time { return now },
+	}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithBudget(b))
+
+	var create = func(user string) error {
+		var _, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+			Model:    models.GPT4oMini,
+			Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+			User:     user,
+		})
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := create("alice"); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+	}
+
+	var be *BudgetExceededError
+	if err := create("alice"); !errors.As(err, &be) {
+		t.Fatalf("expected *BudgetExceededError, got %v", err)
+	}
+	if be.Key != "alice" || be.Tokens != 160 {
+		t.Errorf("unexpected error: %v", be)
+	}
+	if err := create("bob"); err != nil {
+		t.Errorf("expected other users to be unaffected, got %v", err)
+	}
+
+	var tokens, dollars = b.Spent("alice", BudgetLifetime)
+	// 2 * (60 * $0.15/M + 20 * $0.60/M).
+	if tokens != 160 || !approx(dollars, 0.000042) {
+		t.Errorf("unexpected lifetime spend: %d tokens, $%v", tokens, dollars)
+	}
+
+	now = now.Add(time.Minute)
+	if err := create("alice"); err != nil {
+		t.Errorf("expected budget to reset after a minute, got %v", err)
+	}
+}
+
+func TestBudgetWarnAndEstimate(t *testing.T) {
+	var ts = budgetTestServer()
+	defer ts.Close()
+
+	var warnings int
+	var b = &Budget{
+		Limits:   []*BudgetLimit{{Window: BudgetPerDay, Dollars: 0.05}},
+		Estimate: true,
+		Warn: func(ctx context.Context, err *BudgetExceededError) {
+			warnings++
+		},
+	}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithBudget(b))
+
+	// Each request is estimated at $0.02, so the third would exceed the limit.
+	for i := 0; i < 3; i++ {
+		if _, err := client.CreateImage(context.Background(), &CreateImageRequest{Prompt: "a cat"}); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if warnings != 1 {
+		t.Errorf("expected 1 warning, got %d", warnings)
+	}
+	if _, dollars := b.Spent("", BudgetPerDay); !approx(dollars, 0.06) {
+		t.Errorf("expected $0.06 spent, got $%v", dollars)
+	}
+}
+
+func TestBudgetStreaming(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`+"\n\n")
+		_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`+"\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	var b = &Budget{Scope: BudgetPerKey}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithBudget(b))
+
+	var ctx = ContextWithBudgetKey(context.Background(), "team-a")
+	var chunks, errs, err = client.CreateStreamingChatCompletion(ctx, &ChatCompletionRequest{
+		Model:         models.GPT4o,
+		Messages:      []*ChatMessage{{Role: User, Content: "hi"}},
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateStreamingChatCompletion error: %v", err)
+	}
+	for range chunks {
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if tokens, _ := b.Spent("team-a", BudgetLifetime); tokens != 12 {
+		t.Errorf("expected 12 tokens to be recorded, got %d", tokens)
+	}
+}
+
+func TestBudgetRequestsStreamUsage(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			StreamOptions *StreamOptions `json:"stream_options"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StreamOptions == nil ||
+			!req.StreamOptions.IncludeUsage {
+			t.Errorf("expected stream_options.include_usage to be requested")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}`+"\n\n")
+		_, _ = fmt.Fprint(w, `data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`+"\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	var b = &Budget{}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithBudget(b))
+
+	var chunks, errs, err = client.CreateStreamingChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateStreamingChatCompletion error: %v", err)
+	}
+
+	var n int
+	for range chunks {
+		n++
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if n != 1 {
+		t.Errorf("expected the usage chunk not to be sent, got %d chunks", n)
+	}
+	if tokens, _ := b.Spent("", BudgetLifetime); tokens != 12 {
+		t.Errorf("expected 12 tokens to be recorded, got %d", tokens)
+	}
+}
+
+func TestStreamRecordsMissingUsage(t *testing.T) {
+	var recorded []*Usage
+	var res = &OperationResult{
+		Response: &http.Response{Body: io.NopCloser(strings.NewReader(
+			`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"hi"}}]}` + "\n\n"))},
+		record: func(u *Usage) { recorded = append(recorded, u) },
+	}
+
+	var chunks, errs = streamEvents[ChatCompletionChunk](context.Background(), res, 0)
+	for range chunks {
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	// The stream ended without its usage, so it must be recorded as such for its estimated cost to be charged.
+	if len(recorded) != 1 || recorded[0] != nil {
+		t.Errorf("expected the stream to be recorded without usage, got %v", recorded)
+	}
+}
+
+func TestBudgetAudio(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("response_format") {
+		case "verbose_json":
+			_, _ = w.Write([]byte(`{"task":"transcribe","language":"english","duration":30,"text":"<hello>"}`))
+		case "srt":
+			_, _ = w.Write([]byte("1\n00:00:00,000 --> 00:00:05,000\nhello\n\n2\n00:00:55,000 --> 00:01:00,000\nbye\n"))
+		default:
+			_, _ = w.Write([]byte(`{"text":"<hello>"}`))
+		}
+	}))
+	defer ts.Close()
+
+	var f, err = os.CreateTemp(t.TempDir(), "*.mp3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var b = &Budget{}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithBudget(b))
+
+	var verbose = audio.FormatVerboseJSON
+	var srt = audio.FormatSRT
+	var tcs = []struct {
+		format  *audio.Format
+		want    string
+		dollars float64
+	}{
+		// JSON transcripts don't report the duration, so aren't charged.
+		{format: nil, want: `{"text":"<hello>"}`, dollars: 0},
+		{format: &verbose, want: `{"task":"transcribe","language":"english","duration":30,"text":"<hello>"}`, dollars: 0.003},
+		{format: &srt, want: "1\n00:00:00,000 --> 00:00:05,000\nhello\n\n2\n00:00:55,000 --> 00:01:00,000\nbye\n", dollars: 0.006},
+	}
+	for _, tc := range tcs {
+		var _, before = b.Spent("", BudgetLifetime)
+		var got, err = client.TranscribeAudioFile(context.Background(), &AudioTranscriptionRequest{
+			File:           f,
+			Model:          models.Whisper1,
+			ResponseFormat: tc.format,
+		})
+		if err != nil {
+			t.Fatalf("TranscribeAudioFile error: %v", err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("expected the raw transcript %q, got %q", tc.want, got)
+		}
+		if _, after := b.Spent("", BudgetLifetime); !approx(after-before, tc.dollars) {
+			t.Errorf("expected $%v to be charged, got $%v", tc.dollars, after-before)
+		}
+	}
+}
+
+func TestTranscriptDuration(t *testing.T) {
+	var tcs = []struct {
+		format audio.Format
+		body   string
+		want   time.Duration
+	}{
+		{format: audio.FormatVerboseJSON, body: `{"duration":8.5}`, want: 8500 * time.Millisecond},
+		{format: audio.FormatSRT, body: "1\n01:02:03,500 --> 01:02:04,250\nhi\n", want: time.Hour + 2*time.Minute + 4250*time.Millisecond},
+		{format: audio.FormatVTT, body: "WEBVTT\n\n00:01.000 --> 00:02.500\nhi\n", want: 2500 * time.Millisecond},
+		{format: audio.FormatVTT, body: "WEBVTT\n", want: 0},
+	}
+	for _, tc := range tcs {
+		var got, err = transcriptDuration(tc.format, []byte(tc.body))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.format, err)
+		} else if got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.format, tc.want, got)
+		}
+	}
+
+	if _, err := transcriptDuration(audio.FormatText, []byte("hi")); err == nil {
+		t.Error("expected an error for text transcripts")
+	}
+}
+
+func TestBudgetReservesEstimates(t *testing.T) {
+	var ctx = context.Background()
+	var admit = func(b *Budget, payload any, body []byte) (func(), error) {
+		var _, release, err = b.admit(ctx, payload, &request{body: body}, &requestMeta{})
+		return release, err
+	}
+
+	// Each image request is estimated at $0.02, so only two may be in flight within $0.05.
+	var b = &Budget{Limits: []*BudgetLimit{{Window: BudgetPerDay, Dollars: 0.05}}, Estimate: true}
+	var image = &CreateImageRequest{Prompt: "a cat"}
+	var release, err = admit(b, image, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = admit(b, image, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var be *BudgetExceededError
+	if _, err = admit(b, image, nil); !errors.As(err, &be) {
+		t.Fatalf("expected concurrent requests not to overshoot the limit, got %v", err)
+	}
+	release()
+	if _, err = admit(b, image, nil); err != nil {
+		t.Errorf("expected a released reservation to free the budget, got %v", err)
+	}
+
+	// Each body is estimated at 60 tokens.
+	var body = make([]byte, 60*bytesPerToken)
+	b = &Budget{Limits: []*BudgetLimit{{Tokens: 100}}, Estimate: true}
+	if _, err = admit(b, nil, body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = admit(b, nil, body); !errors.As(err, &be) {
+		t.Errorf("expected a request whose estimate would exceed the token limit to be rejected, got %v", err)
+	}
+
+	b = &Budget{Limits: []*BudgetLimit{{Tokens: 100}}}
+	for i := 0; i < 2; i++ {
+		if _, err = admit(b, nil, body); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+	}
+	if _, err = admit(b, nil, body); !errors.As(err, &be) {
+		t.Errorf("expected reservations to count towards the token limit, got %v", err)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/fabiustech/openai/models"
 	"github.com/fabiustech/openai/objects"
 	"github.com/fabiustech/openai/params"
 	"github.com/fabiustech/openai/routes"
+	"github.com/fabiustech/openai/schema"
 )
 
 // ChatRole is an enum of the various message roles.
@@ -24,7 +26,12 @@ const (
 	// be written by a developer to help give examples of desired behavior.
 	Assistant ChatRole = "assistant"
 	// RoleFunction represents a call to a function.
+	//
+	// Deprecated: Use RoleTool with ToolCallID instead.
 	RoleFunction ChatRole = "function"
+	// RoleTool represents the result of a tool call. Messages with this role must set ToolCallID to the ID of the
+	// ToolCall to which they respond.
+	RoleTool ChatRole = "tool"
 )
 
 // FunctionCallResponse represents a response from a function call.
@@ -37,9 +44,62 @@ type FunctionCallResponse struct {
 
 // ChatMessage represents a message in a chat completion.
 type ChatMessage struct {
-	Role         ChatRole              `json:"role"`
-	Content      string                `json:"content,omitempty"`
+	Role ChatRole `json:"role"`
+	// Content is the text content of the message.
+	Content string `json:"content,omitempty"`
+	// ContentParts is the content of the message as an array of typed parts, allowing images and audio to be sent to
+	// models which support them. If non-empty, it is sent instead of Content. Responses are only decoded into
+	// ContentParts if the API returns an array.
+	ContentParts []*ContentPart `json:"-"`
+	// Refusal is the refusal message generated by the model, if it declined to respond. Only set on Assistant messages.
+	Refusal string `json:"refusal,omitempty"`
+	// Name is the name of the author of this message. It is required for RoleFunction messages, where it should be the
+	// name of the function whose result is in Content. For other roles, it can be used to differentiate between
+	// participants of the same role. May contain a-z, A-Z, 0-9, and underscores, with a maximum length of 64 characters.
+	Name string `json:"name,omitempty"`
+	// FunctionCall is the name and arguments of a function that should be called, as generated by the model.
+	//
+	// Deprecated: Use ToolCalls instead.
 	FunctionCall *FunctionCallResponse `json:"function_call,omitempty"`
+	// ToolCalls are the tool calls generated by the model. Only set on Assistant messages.
+	ToolCalls []*ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID is the ID of the ToolCall to which this message responds. Only set on RoleTool messages.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ToolType is an enum of the various tool types.
+type ToolType string
+
+// ToolTypeFunction represents a function tool. It is currently the only supported tool type.
+const ToolTypeFunction ToolType = "function"
+
+// Tool represents a tool the model may call.
+type Tool struct {
+	// Type is the type of the tool. Currently, only ToolTypeFunction is supported.
+	Type ToolType `json:"type"`
+	// Function describes the function which may be called.
+	Function *Function `json:"function"`
+}
+
+// NewFunctionTool returns a *Tool of type ToolTypeFunction which wraps |f|.
+func NewFunctionTool(f *Function) *Tool {
+	return &Tool{
+		Type:     ToolTypeFunction,
+		Function: f,
+	}
+}
+
+// ToolCall represents a call to a tool, as generated by the model.
+type ToolCall struct {
+	// Index is the index of the tool call within the message. Only set on streamed ChatCompletionChunk deltas, where it
+	// identifies which tool call a fragment belongs to.
+	Index *int `json:"index,omitempty"`
+	// ID is the ID of the tool call. Responses to the call must set ChatMessage.ToolCallID to this value.
+	ID string `json:"id,omitempty"`
+	// Type is the type of the tool. Currently, only ToolTypeFunction is supported.
+	Type ToolType `json:"type,omitempty"`
+	// Function is the function that the model called.
+	Function *FunctionCallResponse `json:"function,omitempty"`
 }
 
 // Unmarshal unmarshals the content of the message into the provided value.
@@ -57,11 +117,34 @@ type Function struct {
 	// Parameters are the parameters the functions accepts, described as a JSON Schema object. See the guide
 	// (https://platform.openai.com/docs/guides/gpt/function-calling) for examples, and the JSON Schema reference
 	// (https://json-schema.org/understanding-json-schema/) for documentation about the format.
-	// TODO: Is there a stronger typed representation of this w/o adding a dependency?
+	// Use NewFunction (or the schema package directly) to generate Parameters from a Go type.
 	Parameters json.RawMessage `json:"parameters"`
+	// Strict specifies whether to enable strict schema adherence when generating the function call. If set to true,
+	// the model will follow the exact schema defined in Parameters. Only a subset of JSON Schema is supported when
+	// Strict is true; see schema.StrictFor.
+	// Defaults to false.
+	Strict *bool `json:"strict,omitempty"`
 }
 
-// TODO(Andy): Support streaming.
+// NewFunction returns a *Function named |name| whose Parameters are the JSON Schema generated from A by the schema
+// package.
+func NewFunction[A any](name, description string) (*Function, error) {
+	var s, err = schema.For[A]()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw json.RawMessage
+	if raw, err = s.Raw(); err != nil {
+		return nil, err
+	}
+
+	return &Function{
+		Name:        name,
+		Description: description,
+		Parameters:  raw,
+	}, nil
+}
 
 const (
 	functionCallNone = "none"
@@ -99,21 +182,142 @@ func FunctionCallAuto() *FunctionCall {
 	return &FunctionCall{returnString: params.Optional(functionCallAuto)}
 }
 
+const toolChoiceRequired = "required"
+
+// ToolChoice controls which (if any) tool is called by the model. Use ToolChoiceNone, ToolChoiceAuto,
+// ToolChoiceRequired or ToolChoiceFunction to construct a ToolChoice.
+type ToolChoice struct {
+	Type     ToolType `json:"type"`
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+	returnString *string
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t *ToolChoice) MarshalJSON() ([]byte, error) {
+	if t.returnString != nil {
+		return []byte(fmt.Sprintf(`"%s"`, *t.returnString)), nil
+	}
+
+	type toolChoice ToolChoice
+
+	return json.Marshal((*toolChoice)(t))
+}
+
+// ToolChoiceNone returns a ToolChoice which specifies that the model will not call any tool and instead generates a
+// message.
+func ToolChoiceNone() *ToolChoice {
+	return &ToolChoice{returnString: params.Optional(functionCallNone)}
+}
+
+// ToolChoiceAuto returns a ToolChoice which specifies that the model can pick between generating a message or calling
+// one or more tools.
+func ToolChoiceAuto() *ToolChoice {
+	return &ToolChoice{returnString: params.Optional(functionCallAuto)}
+}
+
+// ToolChoiceRequired returns a ToolChoice which specifies that the model must call one or more tools.
+func ToolChoiceRequired() *ToolChoice {
+	return &ToolChoice{returnString: params.Optional(toolChoiceRequired)}
+}
+
+// ToolChoiceFunction returns a ToolChoice which forces the model to call the function named |name|.
+func ToolChoiceFunction(name string) *ToolChoice {
+	var t = &ToolChoice{Type: ToolTypeFunction}
+	t.Function.Name = name
+
+	return t
+}
+
+// ResponseFormatType is an enum of the various response formats.
+type ResponseFormatType string
+
+const (
+	// ResponseFormatTypeText specifies that the model may respond with any text.
+	ResponseFormatTypeText ResponseFormatType = "text"
+	// ResponseFormatTypeJSONObject specifies that the model must respond with a valid JSON object (JSON mode). When
+	// using JSON mode, the model must also be instructed to produce JSON via a system or user message.
+	ResponseFormatTypeJSONObject ResponseFormatType = "json_object"
+	// ResponseFormatTypeJSONSchema specifies that the model must respond with JSON matching the supplied schema
+	// (Structured Outputs).
+	ResponseFormatTypeJSONSchema ResponseFormatType = "json_schema"
+)
+
+// ResponseFormat specifies the format that the model must output.
+type ResponseFormat struct {
+	// Type is the type of response format.
+	Type ResponseFormatType `json:"type"`
+	// JSONSchema describes the required output. Only set if Type is ResponseFormatTypeJSONSchema.
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema describes the schema the model's response must match when using Structured Outputs.
+type JSONSchema struct {
+	// Name is the name of the response format. Must be a-z, A-Z, 0-9, or contain underscores and dashes, with a
+	// maximum length of 64.
+	Name string `json:"name"`
+	// Description is a description of what the response format is for, used by the model to determine how to respond
+	// in the format.
+	Description string `json:"description,omitempty"`
+	// Schema is the schema for the response format, described as a JSON Schema object.
+	Schema json.RawMessage `json:"schema,omitempty"`
+	// Strict specifies whether to enable strict schema adherence when generating the output. If set to true, the model
+	// will always follow the exact schema defined in Schema.
+	// Defaults to false.
+	Strict *bool `json:"strict,omitempty"`
+}
+
+// ResponseFormatJSONObject returns a *ResponseFormat which enables JSON mode.
+func ResponseFormatJSONObject() *ResponseFormat {
+	return &ResponseFormat{Type: ResponseFormatTypeJSONObject}
+}
+
+// ResponseFormatJSONSchema returns a *ResponseFormat which enables Structured Outputs in strict mode, using |s| as the
+// schema.
+func ResponseFormatJSONSchema(name string, s json.RawMessage) *ResponseFormat {
+	return &ResponseFormat{
+		Type: ResponseFormatTypeJSONSchema,
+		JSONSchema: &JSONSchema{
+			Name:   name,
+			Schema: s,
+			Strict: params.Optional(true),
+		},
+	}
+}
+
 // ChatCompletionRequest contains all relevant fields for requests to the chat completions endpoint.
 type ChatCompletionRequest struct {
 	// Model specifies the ID of the model to use.
 	// See more here: https://platform.openai.com/docs/models/overview.
+	// Use models.NewChatCompletion for models without a predefined constant, e.g. fine-tuned models or Azure
+	// deployments.
 	Model models.ChatCompletion `json:"model"`
 	// Messages are the messages to generate chat completions for, in the chat format.
 	Messages []*ChatMessage `json:"messages"`
 	// Functions are a list of functions the model may generate JSON inputs for.
+	//
+	// Deprecated: Use Tools instead.
 	Functions []*Function `json:"functions,omitempty"`
 	// FunctionCall controls how the model responds to function calls. "none" means the model does not call a function,
 	// and responds to the end-user. "auto" means the model can pick between an end-user or calling a function.
 	// Specifying a particular function via {"name":\ "my_function"} forces the model to call that function.
 	// "none" is the default when no functions are present. "auto" is the default if functions are present.
 	// Use FunctionCallByName to generate a FunctionCall value which will explicitly call a function named |name|.
+	//
+	// Deprecated: Use ToolChoice instead.
 	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+	// Tools are a list of tools the model may call. Currently, only functions are supported as tools.
+	Tools []*Tool `json:"tools,omitempty"`
+	// ToolChoice controls which (if any) tool is called by the model. "none" means the model will not call any tool and
+	// instead generates a message. "auto" means the model can pick between generating a message or calling one or more
+	// tools. "required" means the model must call one or more tools. Use ToolChoiceFunction to force the model to call
+	// a particular function.
+	// "none" is the default when no tools are present. "auto" is the default if tools are present.
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+	// ParallelToolCalls specifies whether to enable parallel function calling during tool use.
+	// Defaults to true.
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
 	// Temperature specifies what sampling temperature to use, between 0 and 2. Higher values like 0.8 will make the
 	// output more random, while lower values like 0.2 will make it more focused and deterministic. OpenAI generally
 	// recommends altering this or top_p but not both.
@@ -151,17 +355,28 @@ type ChatCompletionRequest struct {
 	// User is a unique identifier representing your end-user, which can help OpenAI to monitor and detect abuse.
 	// See more here: https://beta.openai.com/docs/guides/safety-best-practices/end-user-ids
 	User string `json:"user,omitempty"`
+	// ResponseFormat specifies the format that the model must output. Use ResponseFormatJSONObject to enable JSON mode,
+	// which guarantees the message the model generates is valid JSON, or ResponseFormatJSONSchema to enable Structured
+	// Outputs, which ensures the model will match the supplied JSON schema. See CreateStructured.
+	// Defaults to text.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	// StreamOptions specifies options for streaming responses. Only used by CreateStreamingChatCompletion.
+	// Defaults to null.
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// StreamOptions specifies options for streaming chat completion responses.
+type StreamOptions struct {
+	// IncludeUsage specifies that an additional chunk will be streamed before the "[DONE]" event. The Usage field on
+	// this chunk shows the token usage statistics for the entire request, and its Choices will always be empty.
+	// All other chunks will include a nil Usage.
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // CreateChatCompletion creates a chat completion for the provided prompt and parameters.
 func (c *Client) CreateChatCompletion(ctx context.Context, cr *ChatCompletionRequest) (*ChatCompletionResponse, error) {
-	var b, err = c.post(ctx, routes.ChatCompletions, cr)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &ChatCompletionResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.ChatCompletions, cr, resp); err != nil {
 		return nil, err
 	}
 
@@ -183,3 +398,158 @@ type ChatCompletionChoice struct {
 	Message      *ChatMessage `json:"message"`
 	FinishReason string       `json:"finish_reason"`
 }
+
+type streamingChatCompletion struct {
+	Stream bool `json:"stream"`
+	*ChatCompletionRequest
+	// StreamOptions shadows ChatCompletionRequest.StreamOptions, so that the Client can request usage without
+	// modifying the caller's request.
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+}
+
+// CreateStreamingChatCompletion returns two channels: the first will be sent *ChatCompletionChunks as they are
+// received from the API and the second is sent any error(s) encountered while receiving / parsing responses.
+// Both channels will be closed on receipt of the "[DONE]" event or upon the first encountered error.
+// An err is returned if any error occurred prior to receiving an initial response from the API.
+// Use a ChatCompletionAccumulator to reassemble the chunks into a *ChatCompletionResponse.
+func (c *Client) CreateStreamingChatCompletion(ctx context.Context, cr *ChatCompletionRequest) (<-chan *ChatCompletionChunk, <-chan error, error) {
+	var res, err = c.postStream(ctx, routes.ChatCompletions, cr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var chunks, errCh = streamEvents[ChatCompletionChunk](ctx, res, c.maxEventSize)
+
+	return chunks, errCh, nil
+}
+
+// ChatCompletionChunk is a streamed chunk of a chat completion response.
+type ChatCompletionChunk struct {
+	ID      string                       `json:"id"`
+	Object  objects.Object               `json:"object"`
+	Created uint64                       `json:"created"`
+	Choices []*ChatCompletionChunkChoice `json:"choices"`
+	// Usage is only set on the final chunk, and only if StreamOptions.IncludeUsage was specified.
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+func (c *ChatCompletionChunk) usage() *Usage {
+	return c.Usage
+}
+
+// ChatCompletionChunkChoice represents an incremental update to one of the possible chat completions.
+type ChatCompletionChunkChoice struct {
+	// Index is the index of the choice which this chunk updates.
+	Index int `json:"index"`
+	// Delta contains the fragment of the message generated since the previous chunk. Role is only set on the first
+	// chunk for each choice, and FunctionCall.Arguments and ToolCalls[i].Function.Arguments contain fragments of the
+	// JSON encoded arguments.
+	Delta *ChatMessage `json:"delta"`
+	// FinishReason is nil until the final chunk for the choice.
+	FinishReason *string `json:"finish_reason"`
+}
+
+// ChatCompletionAccumulator reassembles the *ChatCompletionChunks streamed by CreateStreamingChatCompletion into a
+// single *ChatCompletionResponse. The zero value is ready to use.
+type ChatCompletionAccumulator struct {
+	resp    *ChatCompletionResponse
+	choices map[int]*ChatCompletionChoice
+}
+
+// Add merges |chunk| into the accumulated response.
+func (a *ChatCompletionAccumulator) Add(chunk *ChatCompletionChunk) {
+	if a.resp == nil {
+		a.resp = &ChatCompletionResponse{
+			ID:      chunk.ID,
+			Object:  objects.ChatCompletion,
+			Created: chunk.Created,
+		}
+		a.choices = make(map[int]*ChatCompletionChoice)
+	}
+
+	if chunk.Usage != nil {
+		a.resp.Usage = chunk.Usage
+	}
+
+	for _, cc := range chunk.Choices {
+		var choice, ok = a.choices[cc.Index]
+		if !ok {
+			choice = &ChatCompletionChoice{
+				Index:   cc.Index,
+				Message: &ChatMessage{Role: Assistant},
+			}
+			a.choices[cc.Index] = choice
+			a.resp.Choices = append(a.resp.Choices, choice)
+		}
+
+		if cc.FinishReason != nil {
+			choice.FinishReason = *cc.FinishReason
+		}
+
+		if cc.Delta != nil {
+			mergeDelta(choice.Message, cc.Delta)
+		}
+	}
+}
+
+// maxToolCalls bounds the index of streamed tool calls, so that a malformed chunk cannot cause an unbounded allocation.
+// It matches the maximum number of tools a request may declare.
+const maxToolCalls = 128
+
+// mergeDelta appends the fragments in |delta| to |msg|. Tool call fragments with an index outside [0, maxToolCalls)
+// are dropped.
+func mergeDelta(msg, delta *ChatMessage) {
+	if delta.Role != "" {
+		msg.Role = delta.Role
+	}
+
+	msg.Content += delta.Content
+	msg.Refusal += delta.Refusal
+
+	if delta.FunctionCall != nil {
+		if msg.FunctionCall == nil {
+			msg.FunctionCall = &FunctionCallResponse{}
+		}
+		msg.FunctionCall.Name += delta.FunctionCall.Name
+		msg.FunctionCall.Arguments += delta.FunctionCall.Arguments
+	}
+
+	for _, tc := range delta.ToolCalls {
+		var i = len(msg.ToolCalls)
+		if tc.Index != nil {
+			i = *tc.Index
+		}
+		if i < 0 || i >= maxToolCalls {
+			continue
+		}
+		for len(msg.ToolCalls) <= i {
+			msg.ToolCalls = append(msg.ToolCalls, &ToolCall{Function: &FunctionCallResponse{}})
+		}
+
+		var call = msg.ToolCalls[i]
+		if tc.ID != "" {
+			call.ID = tc.ID
+		}
+		if tc.Type != "" {
+			call.Type = tc.Type
+		}
+		if tc.Function != nil {
+			call.Function.Name += tc.Function.Name
+			call.Function.Arguments += tc.Function.Arguments
+		}
+	}
+}
+
+// Response returns the response accumulated thus far, with Choices ordered by index. It returns nil if no chunks
+// have been added.
+func (a *ChatCompletionAccumulator) Response() *ChatCompletionResponse {
+	if a.resp == nil {
+		return nil
+	}
+
+	sort.Slice(a.resp.Choices, func(i, j int) bool {
+		return a.resp.Choices[i].Index < a.resp.Choices[j].Index
+	})
+
+	return a.resp
+}
@@ -0,0 +1,209 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/params"
+)
+
+func TestCreateStreamingChatCompletion(t *testing.T) {
+	var events = []string{
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[{"index":0,"delta":{"role":"assistant","content":""},"finish_reason":null}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[{"index":1,"delta":{"role":"assistant","function_call":{"name":"get_weather","arguments":""}},"finish_reason":null}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[{"index":1,"delta":{"function_call":{"arguments":"{\"city\":"}},"finish_reason":null}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[{"index":0,"delta":{"content":", world"},"finish_reason":"stop"}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[{"index":1,"delta":{"function_call":{"arguments":"\"Paris\"}"}},"finish_reason":"function_call"}]}`,
+		`{"id":"1","object":"chat.completion.chunk","created":1,"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}`,
+		`[DONE]`,
+	}
+
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.Error(w, "the resource path doesn't exist", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", e)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer ts.Close()
+
+	var client, _ = newTestClient(ts.URL)
+	var chunks, errs, err = client.CreateStreamingChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:         models.GPT4o,
+		Messages:      []*ChatMessage{{Role: User, Content: "Hello"}},
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateStreamingChatCompletion error: %v", err)
+	}
+
+	var acc = &ChatCompletionAccumulator{}
+	var n int
+loop:
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				break loop
+			}
+			n++
+			acc.Add(chunk)
+		case err = <-errs:
+			if err != nil {
+				t.Fatalf("stream error: %v", err)
+			}
+		}
+	}
+
+	if n != len(events)-1 {
+		t.Fatalf("expected %d chunks, got %d", len(events)-1, n)
+	}
+
+	var resp = acc.Response()
+	if len(resp.Choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(resp.Choices))
+	}
+	if got := resp.Choices[0].Message.Content; got != "Hello, world" {
+		t.Errorf("unexpected content %q", got)
+	}
+	if got := resp.Choices[0].FinishReason; got != "stop" {
+		t.Errorf("unexpected finish reason %q", got)
+	}
+	var fc = resp.Choices[1].Message.FunctionCall
+	if fc == nil || fc.Name != "get_weather" || fc.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected function call %+v", fc)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 12 {
+		t.Errorf("unexpected usage %+v", resp.Usage)
+	}
+}
+
+func TestChatCompletionRequestTools(t *testing.T) {
+	var tcs = []struct {
+		choice *ToolChoice
+		want   string
+	}{
+		{choice: ToolChoiceNone(), want: `"tool_choice":"none"`},
+		{choice: ToolChoiceAuto(), want: `"tool_choice":"auto"`},
+		{choice: ToolChoiceRequired(), want: `"tool_choice":"required"`},
+		{choice: ToolChoiceFunction("get_weather"), want: `"tool_choice":{"type":"function","function":{"name":"get_weather"}}`},
+	}
+
+	for _, tc := range tcs {
+		var b, err = json.Marshal(&ChatCompletionRequest{
+			Model: models.GPT4o,
+			Tools: []*Tool{NewFunctionTool(&Function{
+				Name:       "get_weather",
+				Parameters: json.RawMessage(`{"type":"object"}`),
+			})},
+			ToolChoice:        tc.choice,
+			ParallelToolCalls: params.Optional(false),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{tc.want, `"parallel_tool_calls":false`, `"tools":[{"type":"function","function":{"name":"get_weather"`} {
+			if !strings.Contains(string(b), want) {
+				t.Errorf("expected %s to contain %s", b, want)
+			}
+		}
+	}
+}
+
+func TestChatCompletionAccumulatorToolCalls(t *testing.T) {
+	var chunks = []string{
+		`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+		`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+		`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":-1,"function":{"arguments":"x"}},{"index":1000000000,"function":{"arguments":"x"}}]}}]}`,
+		`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}`,
+	}
+
+	var acc = &ChatCompletionAccumulator{}
+	for _, c := range chunks {
+		var chunk = &ChatCompletionChunk{}
+		if err := json.Unmarshal([]byte(c), chunk); err != nil {
+			t.Fatal(err)
+		}
+		acc.Add(chunk)
+	}
+
+	var msg = acc.Response().Choices[0].Message
+	if len(msg.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(msg.ToolCalls))
+	}
+	if tc := msg.ToolCalls[0]; tc.ID != "call_a" || tc.Function.Name != "get_weather" || tc.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected tool call %+v", tc)
+	}
+	if tc := msg.ToolCalls[1]; tc.ID != "call_b" || tc.Function.Name != "get_time" || tc.Function.Arguments != `{}` {
+		t.Errorf("unexpected tool call %+v", tc)
+	}
+}
+
+func TestChatMessageContentParts(t *testing.T) {
+	var img = image.NewRGBA(image.Rect(0, 0, 1, 1))
+	var imgPart, err = ImagePart(img, ImageDetailLow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(imgPart.ImageURL.URL, "data:image/png;base64,") {
+		t.Fatalf("unexpected image URL %q", imgPart.ImageURL.URL)
+	}
+
+	var msg = &ChatMessage{
+		Role: User,
+		ContentParts: []*ContentPart{
+			TextPart("What's in this image?"),
+			ImageURLPart("https://example.com/cat.png", ImageDetailHigh),
+			InputAudioPart([]byte("RIFF"), InputAudioFormatWAV),
+		},
+	}
+
+	var b []byte
+	if b, err = json.Marshal(msg); err != nil {
+		t.Fatal(err)
+	}
+	var want = `{"role":"user","content":[{"type":"text","text":"What's in this image?"},` +
+		`{"type":"image_url","image_url":{"url":"https://example.com/cat.png","detail":"high"}},` +
+		`{"type":"input_audio","input_audio":{"data":"UklGRg==","format":"wav"}}]}`
+	if string(b) != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, b)
+	}
+
+	var decoded = &ChatMessage{}
+	if err = json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.ContentParts) != 3 || decoded.ContentParts[1].ImageURL.Detail != ImageDetailHigh {
+		t.Fatalf("unexpected message %+v", decoded)
+	}
+	if decoded.Text() != "What's in this image?" {
+		t.Fatalf("unexpected text %q", decoded.Text())
+	}
+
+	// String content is unaffected.
+	if b, err = json.Marshal(ChatMessage{Role: Assistant, Content: "A cat."}); err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"role":"assistant","content":"A cat."}` {
+		t.Fatalf("unexpected encoding %s", b)
+	}
+	decoded = &ChatMessage{}
+	if err = json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Content != "A cat." || decoded.ContentParts != nil {
+		t.Fatalf("unexpected message %+v", decoded)
+	}
+}
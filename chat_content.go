@@ -0,0 +1,214 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ContentPartType is an enum of the various types of message content parts.
+type ContentPartType string
+
+const (
+	// ContentPartTypeText represents a text content part.
+	ContentPartTypeText ContentPartType = "text"
+	// ContentPartTypeImageURL represents an image content part. Only supported on User messages sent to models with
+	// vision capabilities.
+	ContentPartTypeImageURL ContentPartType = "image_url"
+	// ContentPartTypeInputAudio represents an audio content part. Only supported on User messages sent to models with
+	// audio capabilities.
+	ContentPartTypeInputAudio ContentPartType = "input_audio"
+)
+
+// ImageDetail specifies the detail level of an image. See https://platform.openai.com/docs/guides/vision.
+type ImageDetail string
+
+const (
+	// ImageDetailAuto lets the model decide the detail level based on the image size.
+	ImageDetailAuto ImageDetail = "auto"
+	// ImageDetailLow processes a low-resolution (512px x 512px) version of the image, using fewer tokens.
+	ImageDetailLow ImageDetail = "low"
+	// ImageDetailHigh processes the low-resolution image followed by detailed crops of the image.
+	ImageDetailHigh ImageDetail = "high"
+)
+
+// InputAudioFormat is an enum of the supported input audio formats.
+type InputAudioFormat string
+
+const (
+	// InputAudioFormatWAV represents WAV encoded audio.
+	InputAudioFormatWAV InputAudioFormat = "wav"
+	// InputAudioFormatMP3 represents MP3 encoded audio.
+	InputAudioFormatMP3 InputAudioFormat = "mp3"
+)
+
+// ContentPart is a single part of a message's content. Exactly one of Text, ImageURL or InputAudio is set, as
+// indicated by Type.
+type ContentPart struct {
+	Type       ContentPartType `json:"type"`
+	Text       string          `json:"text,omitempty"`
+	ImageURL   *ImageURL       `json:"image_url,omitempty"`
+	InputAudio *InputAudio     `json:"input_audio,omitempty"`
+}
+
+// ImageURL is an image referenced by a ContentPart.
+type ImageURL struct {
+	// URL is either a URL of the image or the base64 encoded image data, as a data URL.
+	URL string `json:"url"`
+	// Detail specifies the detail level of the image.
+	// Defaults to ImageDetailAuto.
+	Detail ImageDetail `json:"detail,omitempty"`
+}
+
+// InputAudio is audio data included in a ContentPart.
+type InputAudio struct {
+	// Data is the base64 encoded audio data.
+	Data string `json:"data"`
+	// Format is the format of the encoded audio data.
+	Format InputAudioFormat `json:"format"`
+}
+
+// TextPart returns a text *ContentPart.
+func TextPart(text string) *ContentPart {
+	return &ContentPart{Type: ContentPartTypeText, Text: text}
+}
+
+// ImageURLPart returns an image *ContentPart which references the image at |url|.
+func ImageURLPart(url string, detail ImageDetail) *ContentPart {
+	return &ContentPart{
+		Type: ContentPartTypeImageURL,
+		ImageURL: &ImageURL{
+			URL:    url,
+			Detail: detail,
+		},
+	}
+}
+
+// ImageDataPart returns an image *ContentPart containing |data| as a base64 encoded data URL. The media type is
+// detected from |data|.
+func ImageDataPart(data []byte, detail ImageDetail) *ContentPart {
+	var url = fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data))
+
+	return ImageURLPart(url, detail)
+}
+
+// ImageFilePart returns an image *ContentPart containing the contents of the file at |path| as a base64 encoded data
+// URL.
+func ImageFilePart(path string, detail ImageDetail) (*ContentPart, error) {
+	var b, err = os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ImageDataPart(b, detail), nil
+}
+
+// ImagePart returns an image *ContentPart containing |img|, encoded as a PNG, as a base64 encoded data URL.
+func ImagePart(img image.Image, detail ImageDetail) (*ContentPart, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+
+	return ImageDataPart(b.Bytes(), detail), nil
+}
+
+// InputAudioPart returns an audio *ContentPart containing |data|, which is encoded in |format|.
+func InputAudioPart(data []byte, format InputAudioFormat) *ContentPart {
+	return &ContentPart{
+		Type: ContentPartTypeInputAudio,
+		InputAudio: &InputAudio{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	}
+}
+
+// InputAudioFilePart returns an audio *ContentPart containing the contents of the file at |path|. The format is
+// determined by the file's extension, which must be ".wav" or ".mp3".
+func InputAudioFilePart(path string) (*ContentPart, error) {
+	var format InputAudioFormat
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		format = InputAudioFormatWAV
+	case ".mp3":
+		format = InputAudioFormatMP3
+	default:
+		return nil, fmt.Errorf("openai: unsupported audio file extension %q", filepath.Ext(path))
+	}
+
+	var b, err = os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return InputAudioPart(b, format), nil
+}
+
+// chatMessage has the same fields as ChatMessage, but none of its methods, so that it can be marshaled by
+// encoding/json without recursing into ChatMessage's MarshalJSON / UnmarshalJSON.
+type chatMessage ChatMessage
+
+// MarshalJSON implements json.Marshaler. If ContentParts is non-empty, it is sent as the message's content (and
+// Content is ignored).
+func (m ChatMessage) MarshalJSON() ([]byte, error) {
+	if len(m.ContentParts) == 0 {
+		return json.Marshal(chatMessage(m))
+	}
+
+	return json.Marshal(struct {
+		chatMessage
+		Content []*ContentPart `json:"content"`
+	}{
+		chatMessage: chatMessage(m),
+		Content:     m.ContentParts,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. String content is decoded into Content, while an array of content parts
+// is decoded into ContentParts.
+func (m *ChatMessage) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		*chatMessage
+		Content json.RawMessage `json:"content"`
+	}
+	aux.chatMessage = (*chatMessage)(m)
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	var content = bytes.TrimSpace(aux.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '[':
+		return json.Unmarshal(content, &m.ContentParts)
+	default:
+		return json.Unmarshal(content, &m.Content)
+	}
+}
+
+// Text returns the text content of the message: Content if ContentParts is empty, otherwise the concatenation of all
+// text parts.
+func (m *ChatMessage) Text() string {
+	if len(m.ContentParts) == 0 {
+		return m.Content
+	}
+
+	var sb strings.Builder
+	for _, p := range m.ContentParts {
+		if p != nil && p.Type == ContentPartTypeText {
+			sb.WriteString(p.Text)
+		}
+	}
+
+	return sb.String()
+}
@@ -4,13 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/fabiustech/openai/routes"
 )
@@ -24,10 +24,42 @@ const (
 
 // Client is OpenAI API client.
 type Client struct {
-	token string
-	orgID *string
+	// token is the API key passed to NewClient. It is not used if credentials is set.
+	token     string
+	orgID     *string
+	projectID *string
+
+	userAgent string
+	headers   http.Header
+
+	client *http.Client
+	retry  *RetryPolicy
+
+	maxEventSize int
+
+	// budget, if set, limits the usage of requests. See WithBudget.
+	budget *Budget
+	// limiter, if set, limits the rate of requests. See WithRateLimiter.
+	limiter *RateLimiter
+
+	// credentials, if set, provides the API key, overriding token. See WithCredentialProvider.
+	credentials *credentialCache
+
+	// azure, if set, configures the Client to call Azure OpenAI Service. See WithAzure.
+	azure *AzureConfig
+	// azureTokens, if set, provides Azure AD tokens. See AzureConfig.TokenProvider.
+	azureTokens *credentialCache
+
+	// middleware wraps every API call. See WithMiddleware.
+	middleware []Middleware
+
+	// validateRequests specifies that requests are validated before being sent. See WithRequestValidation.
+	validateRequests bool
 
 	scheme, host, base, params string
+
+	// err holds the first error encountered while applying Options. If set, it is returned by every request.
+	err error
 }
 
 // SetBaseURL configures the client to make requests to a different base URL.
@@ -35,6 +67,9 @@ type Client struct {
 // in the passed URL string. E.g.
 //
 //	https://{your-resource-name}.openai.azure.com/openai/deployments/{deployment-id}/?api-version=2022-12-01
+//
+// Note that this sends every request to a single deployment, authenticated with an "Authorization" header; prefer
+// WithAzure, which maps models to deployments and uses the "api-key" header or Azure AD tokens.
 func (c *Client) SetBaseURL(u string) error {
 	var parsed, err = url.Parse(u)
 	if err != nil {
@@ -49,277 +84,343 @@ func (c *Client) SetBaseURL(u string) error {
 	return nil
 }
 
-// NewClient creates new OpenAI API client.
-func NewClient(token string) *Client {
-	return &Client{
+// NewClient creates new OpenAI API client. Any |opts| are applied in order.
+func NewClient(token string, opts ...Option) *Client {
+	var c = &Client{
 		token:  token,
+		client: http.DefaultClient,
 		scheme: scheme,
 		host:   host,
 		base:   basePath,
 	}
-}
 
-// NewClientWithOrg creates new OpenAI API client for specified Organization ID.
-func NewClientWithOrg(token, org string) *Client {
-	return &Client{
-		token:  token,
-		orgID:  &org,
-		scheme: scheme,
-		host:   host,
-		base:   basePath,
+	for _, opt := range opts {
+		opt(c)
 	}
-}
 
-func (c *Client) newRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Request, error) {
-	var req, err = http.NewRequestWithContext(ctx, method, url, body)
-	if err != nil {
-		return nil, err
-	}
+	return c
+}
 
-	req.Header.Set("Accept", "application/json; charset=utf-8")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+// NewClientWithOrg creates new OpenAI API client for specified Organization ID. Any |opts| are applied in order,
+// after the Organization ID has been set.
+func NewClientWithOrg(token, org string, opts ...Option) *Client {
+	return NewClient(token, append([]Option{WithOrganization(org)}, opts...)...)
+}
 
-	if c.orgID != nil {
-		req.Header.Set("OpenAI-Organization", *c.orgID)
-	}
+// request describes a single call to the API. Its body is fully buffered so that it can be safely replayed if the
+// request needs to be retried.
+type request struct {
+	method      string
+	route       string
+	body        []byte
+	contentType string
+	// stream specifies that the response is a stream of server-sent events.
+	stream bool
+	// model is the ID of the model the request is for, if known.
+	model string
+	// header contains additional headers, which take precedence over all others.
+	header http.Header
+	// limited specifies that the request was admitted by the Client's RateLimiter, so that every retry must also wait
+	// for capacity.
+	limited bool
+}
 
-	return req, nil
+// requestMeta holds the fields common to most JSON encoded requests.
+type requestMeta struct {
+	Model string `json:"model"`
+	User  string `json:"user"`
 }
 
-func (c *Client) post(ctx context.Context, path string, payload any) ([]byte, error) {
-	var b, err = json.Marshal(payload)
-	if err != nil {
-		return nil, err
+// admit checks |r|, the encoding of |payload|, against the Client's Budget and RateLimiter, waiting for rate limit
+// capacity if necessary. If it may be sent, exactly one of the returned funcs must be called: the first with the
+// Usage and body of its response, or the second if no response was received. Both are nil if the Client has neither.
+func (c *Client) admit(ctx context.Context, r *request, payload any) (func(*Usage, []byte), func(), error) {
+	if c.budget == nil && c.limiter == nil {
+		return nil, nil, nil
 	}
 
-	var req *http.Request
-	req, err = c.newRequest(ctx, "POST", c.reqURL(path), bytes.NewBuffer(b))
-	if err != nil {
-		return nil, err
+	var meta = &requestMeta{Model: r.model}
+	if _, ok := payload.(formWriter); !ok {
+		_ = json.Unmarshal(r.body, meta)
 	}
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
+	var spend, release, err = c.budget.admit(ctx, payload, r, meta)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if release == nil {
+		release = func() {}
 	}
-	defer resp.Body.Close()
 
-	if err = interpretResponse(resp); err != nil {
-		return nil, err
+	var reconcile func(*Usage)
+	if reconcile, err = c.limiter.reserve(ctx, payload, r); err != nil {
+		release()
+		return nil, nil, err
 	}
 
-	return io.ReadAll(resp.Body)
+	return func(u *Usage, body []byte) {
+		if spend != nil {
+			spend(u, body)
+		}
+		if reconcile != nil {
+			reconcile(u)
+		}
+	}, release, nil
 }
 
-const bufferSize = 4096
+func (c *Client) newRequest(ctx context.Context, r *request) (*http.Request, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
 
-func (c *Client) postStream(ctx context.Context, path string, payload any) (<-chan []byte, <-chan error, error) {
-	var b, err = json.Marshal(payload)
+	var u, err = c.reqURL(r)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	var req *http.Request
-	req, err = c.newRequest(ctx, "POST", c.reqURL(path), bytes.NewBuffer(b))
-	if err != nil {
-		return nil, nil, err
+	if req, err = http.NewRequestWithContext(ctx, r.method, u, body); err != nil {
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json; charset=utf-8")
-	req.Header.Set("Accept", "text/event-stream; charset=utf-8")
-	req.Header.Set("Connection", "keep-alive")
-	req.Header.Set("Cache-Control", "no-cache")
 
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req) //nolint:bodyclose // The body is closed in the error check or the go routine.
-	if err != nil {
-		return nil, nil, err
+	for k, v := range c.headers {
+		req.Header[k] = append([]string(nil), v...)
 	}
-	if err = interpretResponse(resp); err != nil {
-		_ = resp.Body.Close()
-		return nil, nil, err
+
+	req.Header.Set("Accept", "application/json; charset=utf-8")
+	if err = c.authorize(ctx, req); err != nil {
+		return nil, err
 	}
 
-	var events = make(chan []byte)
-	var errCh = make(chan error)
-
-	go func() {
-		defer resp.Body.Close()
-		defer close(events)
-		defer close(errCh)
-
-		for {
-			var msg = make([]byte, bufferSize)
-			_, err = resp.Body.Read(msg)
-
-			switch {
-			case errors.Is(err, io.EOF):
-				return
-			case err != nil:
-				errCh <- err
-				return
-			case ctx.Err() != nil:
-				errCh <- ctx.Err()
-				return
-			default:
-				// No-op.
-			}
+	if c.orgID != nil {
+		req.Header.Set("OpenAI-Organization", *c.orgID)
+	}
+	if c.projectID != nil {
+		req.Header.Set("OpenAI-Project", *c.projectID)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+	if r.stream {
+		req.Header.Set("Accept", "text/event-stream; charset=utf-8")
+		req.Header.Set("Connection", "keep-alive")
+		req.Header.Set("Cache-Control", "no-cache")
+	}
 
-			events <- msg
+	for k, v := range r.header {
+		req.Header.Del(k)
+		for _, vv := range v {
+			req.Header.Add(k, vv)
 		}
-	}()
+	}
 
-	return events, errCh, nil
+	return req, nil
 }
 
-// TODO: Combine logic for postAudio and postFile into single "generic" method (postFormData, or similar).
+// send sends |r| using the Client's configured *http.Client and interprets the response, retrying according to the
+// Client's RetryPolicy. The caller is responsible for closing the returned response's Body.
+func (c *Client) send(ctx context.Context, r *request) (*http.Response, error) {
+	var refreshed, sent bool
+	for attempt := 1; ; attempt++ {
+		// The first request was reserved when it was admitted; each retry counts as another request.
+		if sent && r.limited {
+			if err := c.limiter.acquire(ctx, r.model, 0); err != nil {
+				return nil, err
+			}
+		}
+		sent = true
 
-func (c *Client) postAudio(ctx context.Context, ar *AudioTranscriptionRequest) ([]byte, error) {
-	var b bytes.Buffer
-	var w = multipart.NewWriter(&b)
+		var req, err = c.newRequest(ctx, r)
+		if err != nil {
+			return nil, err
+		}
 
-	if err := w.WriteField("model", ar.Model.String()); err != nil {
-		return nil, err
-	}
+		var resp *http.Response
+		resp, err = c.client.Do(req)
+		if err == nil {
+			captureResponseMeta(ctx, resp)
+			c.limiter.observe(r.model, resp.Header)
+			if err = interpretResponse(resp); err == nil {
+				return resp, nil
+			}
+			_ = resp.Body.Close()
 
-	if ar.ResponseFormat != nil {
-		if err := w.WriteField("response_format", ar.ResponseFormat.String()); err != nil {
-			return nil, err
+			// If a cached Credential was rejected, retry once with a fresh one, without counting an attempt.
+			if resp.StatusCode == http.StatusUnauthorized && !refreshed && c.invalidateCredentials(req) {
+				refreshed = true
+				attempt--
+				continue
+			}
 		}
-	}
 
-	if ar.Temperature != nil {
-		if err := w.WriteField("temperature", fmt.Sprintf("%f", *ar.Temperature)); err != nil {
+		var delay, retry = c.retry.next(ctx, attempt, resp, err)
+		if !retry {
 			return nil, err
 		}
-	}
 
-	if ar.Language != nil {
-		if err := w.WriteField("language", *ar.Language); err != nil {
-			return nil, err
+		var t = time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		case <-t.C:
 		}
 	}
+}
 
-	var fw, err = w.CreateFormFile("file", ar.File.Name())
-	if err != nil {
+// do is the innermost Handler: it encodes and sends |op|. Unless |op| streams, the response is read and decoded into
+// |out|, if non-nil.
+func (c *Client) do(ctx context.Context, op *Operation, out any) (*OperationResult, error) {
+	var payload, hideUsage = op.Request, false
+	if op.Stream {
+		payload, hideUsage = streamingPayload(payload, c.budget != nil || c.limiter != nil)
+	}
+	if err := c.validate(payload); err != nil {
 		return nil, err
 	}
 
-	if _, err = io.Copy(fw, ar.File); err != nil {
-		return nil, err
+	var r = &request{
+		method: op.Method,
+		route:  op.Route,
+		stream: op.Stream,
+		model:  op.Model(),
+		header: op.Header,
 	}
 
-	if err = w.Close(); err != nil {
-		return nil, err
+	var record func(*Usage, []byte)
+	var release func()
+	if payload != nil {
+		if form, ok := payload.(formWriter); ok {
+			var b bytes.Buffer
+			var w = multipart.NewWriter(&b)
+			if err := form.writeForm(w); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			r.body, r.contentType = b.Bytes(), w.FormDataContentType()
+		} else {
+			var b, err = json.Marshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			r.body, r.contentType = b, "application/json; charset=utf-8"
+		}
+
+		var err error
+		if record, release, err = c.admit(ctx, r, payload); err != nil {
+			return nil, err
+		}
 	}
 
-	var req *http.Request
-	req, err = c.newRequest(ctx, "POST", c.reqURL(routes.AudioTranscriptions), &b)
+	var resp, err = c.send(ctx, r)
 	if err != nil {
+		if release != nil {
+			release()
+		}
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", w.FormDataContentType())
-
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+	var res = &OperationResult{Response: resp}
+	if op.Stream {
+		if record != nil {
+			res.record = func(u *Usage) { record(u, nil) }
+			res.hideUsage = hideUsage
+		}
+		return res, nil
 	}
 	defer resp.Body.Close()
 
-	if err = interpretResponse(resp); err != nil {
+	if res.Body, err = io.ReadAll(resp.Body); err != nil {
+		if record != nil {
+			// The request was processed, so it is charged its estimate.
+			record(nil, nil)
+		}
 		return nil, err
 	}
 
-	return io.ReadAll(resp.Body)
-}
-
-func (c *Client) postFile(ctx context.Context, fr *FileRequest) ([]byte, error) {
-	var b bytes.Buffer
-	var w = multipart.NewWriter(&b)
-
-	if err := w.WriteField("purposes", fr.Purpose); err != nil {
-		return nil, err
+	if record != nil {
+		var u struct {
+			Usage *Usage `json:"usage"`
+		}
+		_ = json.Unmarshal(res.Body, &u)
+		record(u.Usage, res.Body)
 	}
 
-	var fw, err = w.CreateFormFile("file", fr.File.Name())
-	if err != nil {
-		return nil, err
+	if out != nil {
+		if err = decode(res.Body, out); err != nil {
+			return nil, err
+		}
+		res.Value = out
 	}
 
-	if _, err = io.Copy(fw, fr.File); err != nil {
-		return nil, err
-	}
+	return res, nil
+}
 
-	if err = w.Close(); err != nil {
-		return nil, err
+// decode decodes the response body |b| into |out|: as JSON, or as the raw body if |out| is a *[]byte.
+func decode(b []byte, out any) error {
+	if raw, ok := out.(*[]byte); ok {
+		*raw = b
+		return nil
 	}
 
-	var req *http.Request
-	req, err = c.newRequest(ctx, "POST", c.reqURL(routes.Files), &b)
-	if err != nil {
-		return nil, err
-	}
+	return json.Unmarshal(b, out)
+}
 
-	req.Header.Set("Content-Type", w.FormDataContentType())
+func (c *Client) post(ctx context.Context, path string, payload, out any) error {
+	var _, err = c.call(ctx, &Operation{Method: http.MethodPost, Route: path, Request: payload}, out)
 
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if err = interpretResponse(resp); err != nil {
-		return nil, err
-	}
+	return err
+}
 
-	return io.ReadAll(resp.Body)
+// postStream sends |payload| to |path| as a streaming request. Events can be read from the Body of the returned
+// OperationResult's Response with streamEvents, which closes it.
+func (c *Client) postStream(ctx context.Context, path string, payload any) (*OperationResult, error) {
+	return c.call(ctx, &Operation{Method: http.MethodPost, Route: path, Request: payload, Stream: true}, nil)
 }
 
-func (c *Client) get(ctx context.Context, path string) ([]byte, error) {
-	var req, err = c.newRequest(ctx, "GET", c.reqURL(path), nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) postAudio(ctx context.Context, ar *AudioTranscriptionRequest, out any) error {
+	var _, err = c.call(ctx, &Operation{Method: http.MethodPost, Route: routes.AudioTranscriptions, Request: ar}, out)
 
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return err
+}
 
-	if err = interpretResponse(resp); err != nil {
-		return nil, err
-	}
+func (c *Client) postFile(ctx context.Context, fr *FileRequest, out any) error {
+	var _, err = c.call(ctx, &Operation{Method: http.MethodPost, Route: routes.Files, Request: fr}, out)
 
-	return io.ReadAll(resp.Body)
+	return err
 }
 
-func (c *Client) delete(ctx context.Context, path string) ([]byte, error) {
-	var req, err = c.newRequest(ctx, "DELETE", c.reqURL(path), nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	var _, err = c.call(ctx, &Operation{Method: http.MethodGet, Route: path}, out)
 
-	var resp *http.Response
-	resp, err = http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return err
+}
 
-	if err = interpretResponse(resp); err != nil {
-		return nil, err
-	}
+func (c *Client) delete(ctx context.Context, path string, out any) error {
+	var _, err = c.call(ctx, &Operation{Method: http.MethodDelete, Route: path}, out)
 
-	return io.ReadAll(resp.Body)
+	return err
 }
 
-func (c *Client) reqURL(route string) string {
+func (c *Client) reqURL(r *request) (string, error) {
+	var route = r.route
+	if c.azure != nil {
+		var err error
+		if route, err = c.azure.deploymentPath(route, r.model); err != nil {
+			return "", err
+		}
+	}
+
 	var u = &url.URL{
 		Scheme:   c.scheme,
 		Host:     c.host,
@@ -327,22 +428,31 @@ func (c *Client) reqURL(route string) string {
 		RawQuery: c.params,
 	}
 
-	return u.String()
+	return u.String(), nil
 }
 
 func interpretResponse(resp *http.Response) error {
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
 		var b, err = io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("error, HTTP status code: %d", resp.StatusCode)
+			return &Error{
+				StatusCode: resp.StatusCode,
+				Message:    fmt.Sprintf("error, HTTP status code: %d", resp.StatusCode),
+				RequestID:  resp.Header.Get(headerRequestID),
+			}
 		}
 
 		var ret = &wrappedError{}
-		if json.Unmarshal(b, ret) != nil {
-			return fmt.Errorf("error, HTTP status code: %d, msg: %s", resp.StatusCode, string(b))
+		if json.Unmarshal(b, ret) != nil || ret.Err == nil {
+			return &Error{
+				StatusCode: resp.StatusCode,
+				Message:    fmt.Sprintf("error, HTTP status code: %d, msg: %s", resp.StatusCode, string(b)),
+				RequestID:  resp.Header.Get(headerRequestID),
+			}
 		}
 
 		ret.Err.StatusCode = resp.StatusCode
+		ret.Err.RequestID = resp.Header.Get(headerRequestID)
 
 		return ret.Err
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -375,3 +376,139 @@ func OpenAITestServer() *httptest.Server {
 		}
 	}))
 }
+
+func TestClientOptions(t *testing.T) {
+	var got http.Header
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer ts.Close()
+
+	var transportUsed bool
+	var hc = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		transportUsed = true
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	var client = NewClientWithOrg(testToken, "org-123",
+		WithHTTPClient(hc),
+		WithBaseURL(ts.URL+"/v1"),
+		WithProject("proj-456"),
+		WithUserAgent("test-agent/1.0"),
+		WithDefaultHeaders(http.Header{"x-custom": []string{"value"}}),
+	)
+
+	if _, err := client.ListFiles(context.Background()); err != nil {
+		t.Fatalf("ListFiles error: %v", err)
+	}
+
+	if !transportUsed {
+		t.Fatal("expected configured *http.Client to be used")
+	}
+
+	for k, v := range map[string]string{
+		"Authorization":       "Bearer " + testToken,
+		"Openai-Organization": "org-123",
+		"Openai-Project":      "proj-456",
+		"User-Agent":          "test-agent/1.0",
+		"X-Custom":            "value",
+	} {
+		if got.Get(k) != v {
+			t.Errorf("expected header %s=%q, got %q", k, v, got.Get(k))
+		}
+	}
+}
+
+func TestClientOptionsBadBaseURL(t *testing.T) {
+	var client = NewClient(testToken, WithBaseURL("://bad"))
+	if _, err := client.ListFiles(context.Background()); err == nil {
+		t.Fatal("expected error from invalid base URL")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRetries(t *testing.T) {
+	var attempts int
+	var bodies []string
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		var b, _ = io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+
+		switch attempts {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"slow down","type":"requests"}}`))
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`<html>bad gateway</html>`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"file-123","object":"file"}`))
+		}
+	}))
+	defer ts.Close()
+
+	var f, err = os.CreateTemp(t.TempDir(), "*.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.WriteString(`{"prompt":"a","completion":"b"}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithRetryPolicy(&RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+	}))
+
+	var file *File
+	file, err = client.UploadFile(context.Background(), &FileRequest{File: f, Purpose: "fine-tune"})
+	if err != nil {
+		t.Fatalf("UploadFile error: %v", err)
+	}
+	if file.ID != "file-123" {
+		t.Fatalf("unexpected file ID %q", file.ID)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	for _, b := range bodies[1:] {
+		if b != bodies[0] || !strings.Contains(b, `{"prompt":"a","completion":"b"}`) {
+			t.Fatal("expected request body to be replayed on retry")
+		}
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	var attempts int
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":{"message":"oops","type":"server_error"}}`))
+	}))
+	defer ts.Close()
+
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithRetryPolicy(&RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+	}))
+
+	var _, err = client.ListFiles(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected *Error with status 500, got %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+}
@@ -2,10 +2,7 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"io"
-	"strings"
 
 	"github.com/fabiustech/openai/models"
 	"github.com/fabiustech/openai/objects"
@@ -118,15 +115,14 @@ type CompletionResponse[T models.Completion | models.FineTunedModel] struct {
 	Usage   *Usage              `json:"usage,omitempty"`
 }
 
+func (c *CompletionResponse[T]) usage() *Usage {
+	return c.Usage
+}
+
 // CreateCompletion creates a completion for the provided prompt and parameters.
 func (c *Client) CreateCompletion(ctx context.Context, cr *CompletionRequest[models.Completion]) (*CompletionResponse[models.Completion], error) {
-	var b, err = c.post(ctx, routes.Completions, cr)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &CompletionResponse[models.Completion]{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.Completions, cr, resp); err != nil {
 		return nil, err
 	}
 
@@ -134,7 +130,8 @@ func (c *Client) CreateCompletion(ctx context.Context, cr *CompletionRequest[mod
 }
 
 type streamingCompletion struct {
-	Stream bool `json:"stream"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
 	*CompletionRequest[models.Completion]
 }
 
@@ -143,97 +140,26 @@ type streamingCompletion struct {
 // Both channels will be closed on receipt of the "[DONE]" event or upon the first encountered error.
 // An err is returned if any error occurred prior to receiving an initial response from the API.
 func (c *Client) CreateStreamingCompletion(ctx context.Context, cr *CompletionRequest[models.Completion]) (<-chan *CompletionResponse[models.Completion], <-chan error, error) {
-	var receive, errs, err = c.postStream(ctx, routes.Completions, &streamingCompletion{
-		Stream:            true,
-		CompletionRequest: cr,
-	})
+	var res, err = c.postStream(ctx, routes.Completions, cr)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var resps = make(chan *CompletionResponse[models.Completion])
-	var errCh = make(chan error)
-
-	go func() {
-		defer close(resps)
-		defer close(errCh)
-
-		for {
-			select {
-			case b := <-receive:
-				var events [][]byte
-				var done bool
-				events, err = parseEvents(b)
-				if errors.Is(err, io.EOF) {
-					done = true
-				}
-
-				for _, event := range events {
-					var resp = &CompletionResponse[models.Completion]{}
-
-					if err = json.Unmarshal(event, resp); err != nil {
-						errCh <- err
-						return
-					}
-
-					resps <- resp
-				}
-
-				if done {
-					return
-				}
-			case err = <-errs:
-				errCh <- err
-				return
-			case <-ctx.Done():
-				errCh <- ctx.Err()
-				return
-			}
-		}
-	}()
+	var resps, errCh = streamEvents[CompletionResponse[models.Completion]](ctx, res, c.maxEventSize)
 
 	return resps, errCh, nil
 }
 
-const eventPrefix = "data: "
-const eof = "[DONE]"
-
-// ErrBadPrefix is returned if we attempt to parse a Read from the response Body
-// that doesn't begin with "data: ".
+// ErrBadPrefix was returned if we attempted to parse a Read from the response Body that didn't begin with "data: ".
+//
+// Deprecated: Streams are now decoded by a spec-compliant server-sent event reader, and ErrBadPrefix is no longer
+// returned.
 var ErrBadPrefix = errors.New("unexpected event received")
 
-func parseEvents(b []byte) ([][]byte, error) {
-	if !strings.HasPrefix(string(b), eventPrefix) {
-		return nil, ErrBadPrefix
-	}
-
-	var split = strings.Split(string(b), eventPrefix)
-	var out [][]byte
-
-	var err error
-
-	for _, event := range split[1:] {
-		var msg = strings.TrimRight(event, "\r\n\x00")
-		if msg == eof {
-			err = io.EOF
-			continue
-		}
-
-		out = append(out, []byte(msg))
-	}
-
-	return out, err
-}
-
 // CreateFineTunedCompletion creates a completion for the provided prompt and parameters, using a fine-tuned model.
 func (c *Client) CreateFineTunedCompletion(ctx context.Context, cr *CompletionRequest[models.FineTunedModel]) (*CompletionResponse[models.FineTunedModel], error) {
-	var b, err = c.post(ctx, routes.Completions, cr)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &CompletionResponse[models.FineTunedModel]{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.Completions, cr, resp); err != nil {
 		return nil, err
 	}
 
@@ -1,58 +1,67 @@
 package openai
 
 import (
+	"context"
 	"errors"
-	"io"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/fabiustech/openai/models"
 )
 
-func TestParseEvents(t *testing.T) {
-	var tcs = []struct {
-		in  []byte
-		out [][]byte
-		err error
-	}{
-		{
-			in:  []byte(`bad-prefix: {}`),
-			err: ErrBadPrefix,
-		},
-		{
-			in: []byte(`data: {}`),
-			out: [][]byte{
-				[]byte(`data: {}`),
-			},
-		},
-		{
-			in: []byte(`data: {}
-
-data: {}
-
-`),
-			out: [][]byte{
-				[]byte(`data: {}`),
-				[]byte(`data: {}`),
-			},
-		},
-		{
-			in: []byte(`data: {}
-
-data: [DONE]
-
-`),
-			out: [][]byte{
-				[]byte(`data: {}`),
-			},
-			err: io.EOF,
-		},
+func TestCreateStreamingCompletion(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Split events across writes and mix line endings to exercise the event reader.
+		_, _ = fmt.Fprint(w, ": keep-alive\r\n\r\ndata: {\"id\":\"1\",\"choices\":[{\"text\":\"Lorem\"}]}\r")
+		w.(http.Flusher).Flush()
+		_, _ = fmt.Fprint(w, "\n\r\ndata: {\"id\":\"2\",\"choices\":[{\"text\":\" ipsum\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	var client, _ = newTestClient(ts.URL)
+	var resps, errs, err = client.CreateStreamingCompletion(context.Background(), &CompletionRequest[models.Completion]{
+		Prompt: "Lorem",
+		Model:  models.TextDavinci003,
+	})
+	if err != nil {
+		t.Fatalf("CreateStreamingCompletion error: %v", err)
+	}
+
+	var text string
+	for resp := range resps {
+		text += resp.Choices[0].Text
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if text != "Lorem ipsum" {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
+
+func TestCreateStreamingCompletionError(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "data: {\"error\":{\"message\":\"overloaded\",\"type\":\"server_error\"}}\n\n")
+	}))
+	defer ts.Close()
+
+	var client, _ = newTestClient(ts.URL)
+	var resps, errs, err = client.CreateStreamingCompletion(context.Background(), &CompletionRequest[models.Completion]{
+		Model: models.TextDavinci003,
+	})
+	if err != nil {
+		t.Fatalf("CreateStreamingCompletion error: %v", err)
+	}
+
+	for range resps {
+		t.Fatal("expected no responses")
 	}
 
-	for _, tc := range tcs {
-		var out, err = parseEvents(tc.in)
-		if len(out) != len(tc.out) {
-			t.Fatal("mismatched event counts")
-		}
-		if !errors.Is(err, tc.err) {
-			t.Fatalf("expected err=%v, got err=%v", tc.err, err)
-		}
+	var apiErr *Error
+	if err = <-errs; !errors.As(err, &apiErr) || apiErr.Message != "overloaded" {
+		t.Fatalf("expected *Error, got %v", err)
 	}
 }
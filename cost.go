@@ -0,0 +1,155 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fabiustech/openai/images"
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/tokenizer"
+)
+
+// ErrNoPricing is returned when the cost of using a model is requested but the model has no models.Pricing. Use
+// models.SetPricing to add or override prices.
+var ErrNoPricing = errors.New("openai: no pricing for model")
+
+// perMillion is the number of tokens to which token prices apply.
+const perMillion = 1e6
+
+// Cost returns the cost of |u|, in US dollars, for requests to |model|. Cached prompt tokens are billed at the
+// models.Pricing CachedInput price.
+func (u *Usage) Cost(model fmt.Stringer) (float64, error) {
+	var p, err = pricingOf(model)
+	if err != nil {
+		return 0, err
+	}
+
+	return u.cost(p), nil
+}
+
+// cost returns the cost of |u| at prices |p|.
+func (u *Usage) cost(p models.Pricing) float64 {
+	var cached int
+	if u.PromptTokensDetails != nil {
+		cached = u.PromptTokensDetails.CachedTokens
+	}
+
+	return tokenCost(p, u.PromptTokens-cached, cached, u.CompletionTokens)
+}
+
+// AudioCost returns the cost, in US dollars, of transcribing or translating |d| of audio with |model|.
+func AudioCost(model models.Audio, d time.Duration) (float64, error) {
+	var p, err = pricingOf(model)
+	if err != nil {
+		return 0, err
+	}
+
+	return p.AudioMinute * d.Minutes(), nil
+}
+
+// EstimateCost returns the maximum cost, in US dollars, of the request, before it is sent. The prompt is counted with
+// CountChatTokens, so the encoding for the model must be available; see the tokenizer package. Unless MaxTokens is
+// set, every choice is assumed to generate the model's maximum number of output tokens.
+func (r *ChatCompletionRequest) EstimateCost() (float64, error) {
+	var p, err = pricingOf(r.Model)
+	if err != nil {
+		return 0, err
+	}
+
+	var prompt int
+	if prompt, err = CountChatTokens(r.Model, r.Messages, r.functions()); err != nil {
+		return 0, err
+	}
+
+	var output = r.MaxTokens
+	if output == 0 {
+		var caps, ok = r.Model.Capabilities()
+		if !ok || caps.MaxOutputTokens == 0 {
+			return 0, fmt.Errorf("openai: cannot estimate output of model %q: set MaxTokens", r.Model)
+		}
+		output = caps.MaxOutputTokens
+	}
+
+	if r.N > 1 {
+		output *= r.N
+	}
+
+	return tokenCost(p, prompt, 0, output), nil
+}
+
+// EstimateCost returns the cost, in US dollars, of the request, before it is sent. The input is counted with
+// tokenizer.ForModel, so the encoding for the model must be available; see the tokenizer package.
+func (r *EmbeddingRequest) EstimateCost() (float64, error) {
+	var p, err = pricingOf(r.Model)
+	if err != nil {
+		return 0, err
+	}
+
+	var enc *tokenizer.Encoding
+	if enc, err = tokenizer.ForModel(r.Model); err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, in := range r.Input {
+		n += enc.Count(in)
+	}
+
+	return tokenCost(p, n, 0, 0), nil
+}
+
+// EstimateCost returns the cost, in US dollars, of the request, before it is sent.
+func (r *CreateImageRequest) EstimateCost() (float64, error) {
+	return imageCost(r.N, r.Size)
+}
+
+// EstimateCost returns the cost, in US dollars, of the request, before it is sent.
+func (r *EditImageRequest) EstimateCost() (float64, error) {
+	return imageCost(r.N, r.Size)
+}
+
+// EstimateCost returns the cost, in US dollars, of the request, before it is sent.
+func (r *VariationImageRequest) EstimateCost() (float64, error) {
+	return imageCost(r.N, r.Size)
+}
+
+func pricingOf(model fmt.Stringer) (models.Pricing, error) {
+	var p, ok = models.PriceOf(model.String())
+	if !ok {
+		return models.Pricing{}, fmt.Errorf("%w %q", ErrNoPricing, model.String())
+	}
+
+	return p, nil
+}
+
+func tokenCost(p models.Pricing, input, cached, output int) float64 {
+	var cachedInput = p.CachedInput
+	if cachedInput == 0 {
+		cachedInput = p.Input
+	}
+
+	return (float64(input)*p.Input + float64(cached)*cachedInput + float64(output)*p.Output) / perMillion
+}
+
+// imageCost returns the cost of generating |n| images of |size| with models.DALLE2, applying the API's defaults.
+func imageCost(n int, size images.Size) (float64, error) {
+	var p, ok = models.PriceOf(models.DALLE2)
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrNoPricing, models.DALLE2)
+	}
+
+	if size == images.SizeInvalid {
+		size = images.Size1024x1024
+	}
+	var price, priced = p.Images[size]
+	if !priced {
+		return 0, fmt.Errorf("openai: no pricing for images of size %q", size)
+	}
+
+	if n == 0 {
+		n = 1
+	}
+
+	return price * float64(n), nil
+}
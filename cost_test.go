@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fabiustech/openai/images"
+	"github.com/fabiustech/openai/models"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestUsageCost(t *testing.T) {
+	var u = &Usage{
+		PromptTokens:        2000,
+		CompletionTokens:    500,
+		TotalTokens:         2500,
+		PromptTokensDetails: &PromptTokensDetails{CachedTokens: 1000},
+	}
+
+	var cost, err = u.Cost(models.GPT4o)
+	if err != nil {
+		t.Fatalf("Cost error: %v", err)
+	}
+	// 1000 * $2.50/M + 1000 * $1.25/M + 500 * $10/M.
+	if want := 0.00875; !approx(cost, want) {
+		t.Errorf("expected cost %v, got %v", want, cost)
+	}
+
+	// Without a cached input price, cached tokens are billed at the input price.
+	if cost, err = u.Cost(models.GPT4); err != nil {
+		t.Fatalf("Cost error: %v", err)
+	}
+	if want := 0.09; !approx(cost, want) {
+		t.Errorf("expected cost %v, got %v", want, cost)
+	}
+
+	var ft = models.NewChatCompletion("ft:gpt-4o-mini-2024-07-18:acme::abc123")
+	if _, err = u.Cost(ft); !errors.Is(err, ErrNoPricing) {
+		t.Fatalf("expected ErrNoPricing, got %v", err)
+	}
+
+	models.SetPricing(ft.String(), models.Pricing{Input: 0.3, Output: 1.2})
+	if cost, err = u.Cost(ft); err != nil {
+		t.Fatalf("Cost error: %v", err)
+	}
+	if want := 0.0012; !approx(cost, want) {
+		t.Errorf("expected cost %v, got %v", want, cost)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	var cost, err = (&CreateImageRequest{Prompt: "a cat", N: 3, Size: images.Size512x512}).EstimateCost()
+	if err != nil {
+		t.Fatalf("EstimateCost error: %v", err)
+	}
+	if want := 0.054; !approx(cost, want) {
+		t.Errorf("expected cost %v, got %v", want, cost)
+	}
+
+	if cost, err = (&VariationImageRequest{}).EstimateCost(); err != nil {
+		t.Fatalf("EstimateCost error: %v", err)
+	}
+	if want := 0.02; !approx(cost, want) {
+		t.Errorf("expected default cost %v, got %v", want, cost)
+	}
+
+	if cost, err = AudioCost(models.Whisper1, 90*time.Second); err != nil {
+		t.Fatalf("AudioCost error: %v", err)
+	}
+	if want := 0.009; !approx(cost, want) {
+		t.Errorf("expected cost %v, got %v", want, cost)
+	}
+}
@@ -0,0 +1,217 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credential is an API key or token which authenticates requests.
+type Credential struct {
+	// Token is the API key or token.
+	Token string
+	// Expiry is the time at which the Credential expires, after which it is no longer used. If zero, the Credential
+	// never expires.
+	Expiry time.Time
+}
+
+// CredentialProvider provides the Credentials which authenticate requests, e.g. by reading a secrets file, requesting
+// an Azure AD token or calling a secrets manager. See WithCredentialProvider.
+type CredentialProvider interface {
+	// Credential returns the current Credential. The Client caches it until it is about to expire, so implementations
+	// need not cache Credentials themselves.
+	Credential(ctx context.Context) (*Credential, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context) (*Credential, error)
+
+// Credential implements CredentialProvider.
+func (f CredentialProviderFunc) Credential(ctx context.Context) (*Credential, error) {
+	return f(ctx)
+}
+
+// WithCredentialProvider configures the Client to authenticate requests with Credentials from |p| rather than the
+// token passed to NewClient, e.g. to rotate keys without rebuilding the Client. |p| is queried before the first
+// request, and then whenever the cached Credential is about to expire or is rejected by the API with a 401 status
+// code, in which case the request is retried once with a fresh Credential. If |p| fails, the cached Credential is
+// used until it expires. For Azure OpenAI Service, Credentials are sent as the "api-key" header; use
+// AzureConfig.TokenProvider for Azure AD tokens.
+func WithCredentialProvider(p CredentialProvider) Option {
+	return func(c *Client) {
+		c.credentials = newCredentialCache(p)
+	}
+}
+
+// FileCredentials returns a CredentialProvider which reads the API key from the file at |path| (e.g. a mounted
+// secret), ignoring surrounding whitespace. The file is read again every |ttl|, so that the key can be rotated by
+// replacing it. If |ttl| is not positive, the file is read before every request.
+func FileCredentials(path string, ttl time.Duration) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credential, error) {
+		var b, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var token = strings.TrimSpace(string(b))
+		if token == "" {
+			return nil, fmt.Errorf("openai: credential file %s is empty", path)
+		}
+
+		return &Credential{Token: token, Expiry: time.Now().Add(ttl)}, nil
+	})
+}
+
+// credentialRefreshWindow is how long before it expires a cached Credential is refreshed, so that it doesn't expire
+// while a request is in flight. Credentials with shorter lifetimes are refreshed halfway through them instead.
+const credentialRefreshWindow = 30 * time.Second
+
+// errNoCredential is returned if a CredentialProvider returns neither a Credential nor an error.
+var errNoCredential = errors.New("openai: credential provider returned no credential")
+
+// credentialCache caches the Credentials of a CredentialProvider.
+type credentialCache struct {
+	provider CredentialProvider
+
+	// refreshing serializes calls to provider, so that concurrent requests share a single refresh.
+	refreshing chan struct{}
+
+	mu   sync.Mutex
+	cred *Credential
+	// window is how long before it expires cred is refreshed.
+	window time.Duration
+	now    func() time.Time
+}
+
+func newCredentialCache(p CredentialProvider) *credentialCache {
+	return &credentialCache{
+		provider:   p,
+		refreshing: make(chan struct{}, 1),
+		now:        time.Now,
+	}
+}
+
+// token returns the token of the cached Credential, refreshing it first if it is about to expire.
+func (cc *credentialCache) token(ctx context.Context) (string, error) {
+	if cred := cc.cached(true); cred != nil {
+		return cred.Token, nil
+	}
+
+	select {
+	case cc.refreshing <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-cc.refreshing }()
+
+	// Another request may have refreshed the Credential while we waited.
+	if cred := cc.cached(true); cred != nil {
+		return cred.Token, nil
+	}
+
+	var cred, err = cc.provider.Credential(ctx)
+	if err == nil && (cred == nil || cred.Token == "") {
+		err = errNoCredential
+	}
+	if err != nil {
+		if cred = cc.cached(false); cred != nil {
+			return cred.Token, nil
+		}
+		return "", err
+	}
+
+	cc.mu.Lock()
+	cc.cred, cc.window = cred, credentialRefreshWindow
+	if lifetime := cred.Expiry.Sub(cc.now()); !cred.Expiry.IsZero() && lifetime/2 < cc.window {
+		cc.window = lifetime / 2
+	}
+	cc.mu.Unlock()
+
+	return cred.Token, nil
+}
+
+// cached returns the cached Credential, or nil if there is none or it has expired. If |early|, it also returns nil if
+// the Credential is due to be refreshed.
+func (cc *credentialCache) cached(early bool) *Credential {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	var window time.Duration
+	if early {
+		window = cc.window
+	}
+	if cc.cred == nil || (!cc.cred.Expiry.IsZero() && !cc.now().Add(window).Before(cc.cred.Expiry)) {
+		return nil
+	}
+
+	return cc.cred
+}
+
+// invalidate discards the cached Credential if its token is one of |tokens|, reporting whether it did.
+func (cc *credentialCache) invalidate(tokens ...string) bool {
+	if cc == nil {
+		return false
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.cred == nil {
+		return false
+	}
+	for _, t := range tokens {
+		if t == cc.cred.Token {
+			cc.cred = nil
+			return true
+		}
+	}
+
+	return false
+}
+
+// authorize sets the authentication header of |req|: an Azure AD bearer token or "api-key" for Azure, or a bearer
+// token otherwise.
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	if c.azureTokens != nil {
+		var token, err = c.azureTokens.token(ctx)
+		if err != nil {
+			return fmt.Errorf("openai: getting Azure AD token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		return nil
+	}
+
+	var token = c.token
+	if c.credentials != nil {
+		var err error
+		if token, err = c.credentials.token(ctx); err != nil {
+			return fmt.Errorf("openai: getting credential: %w", err)
+		}
+	}
+
+	if c.azure != nil {
+		req.Header.Set("api-key", token)
+	} else {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	return nil
+}
+
+// invalidateCredentials discards any cached Credential which |req| was authenticated with, reporting whether it did.
+func (c *Client) invalidateCredentials(req *http.Request) bool {
+	var used = []string{strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), req.Header.Get("api-key")}
+
+	var invalidated = c.credentials.invalidate(used...)
+	if c.azureTokens.invalidate(used...) {
+		invalidated = true
+	}
+
+	return invalidated
+}
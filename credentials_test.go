@@ -0,0 +1,98 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCredentialProvider(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer new-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid key","type":"invalid_request_error","code":"invalid_api_key"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer ts.Close()
+
+	var keys = []string{"old-key", "new-key"}
+	var calls int
+	var provider = CredentialProviderFunc(func(context.Context) (*Credential, error) {
+		calls++
+		if calls > len(keys) {
+			return nil, errors.New("secrets unavailable")
+		}
+		return &Credential{Token: keys[calls-1], Expiry: time.Now().Add(time.Hour)}, nil
+	})
+
+	var client = NewClient("unused", WithBaseURL(ts.URL+"/v1"), WithCredentialProvider(provider))
+
+	// The old key is rejected, so it is refreshed and the request retried once.
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	if calls != 2 || len(seen) != 2 || seen[0] != "Bearer old-key" {
+		t.Fatalf("expected the rejected key to be refreshed, got %d calls and requests %v", calls, seen)
+	}
+
+	// The new key is cached.
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the credential to be cached, got %d calls", calls)
+	}
+
+	// Once it is about to expire, it is refreshed; if that fails, it is used until it expires.
+	client.credentials.now = func() time.Time { return time.Now().Add(time.Hour - time.Second) }
+	if _, err := client.ListModels(context.Background()); err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected the credential to be refreshed, got %d calls", calls)
+	}
+
+	client.credentials.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if _, err := client.ListModels(context.Background()); err == nil {
+		t.Error("expected an error once the credential expired")
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte("sk-first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The TTL is shorter than credentialRefreshWindow, but the key is still cached for most of it.
+	var cc = newCredentialCache(FileCredentials(path, 10*time.Second))
+	if token, err := cc.token(context.Background()); err != nil || token != "sk-first" {
+		t.Fatalf("expected sk-first, got %q, %v", token, err)
+	}
+
+	if err := os.WriteFile(path, []byte("sk-second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if token, _ := cc.token(context.Background()); token != "sk-first" {
+		t.Errorf("expected the cached sk-first, got %q", token)
+	}
+
+	cc.now = func() time.Time { return time.Now().Add(10 * time.Second) }
+	if token, _ := cc.token(context.Background()); token != "sk-second" {
+		t.Errorf("expected the rotated sk-second, got %q", token)
+	}
+}
@@ -2,7 +2,6 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/fabiustech/openai/models"
 	"github.com/fabiustech/openai/objects"
@@ -51,13 +50,8 @@ type EditsResponse struct {
 
 // CreateEdit creates a new edit for the provided input, instruction, and parameters.
 func (c *Client) CreateEdit(ctx context.Context, er *EditsRequest) (*EditsResponse, error) {
-	var b, err = c.post(ctx, routes.Edits, er)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &EditsResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.Edits, er, resp); err != nil {
 		return nil, err
 	}
 
@@ -2,7 +2,6 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/fabiustech/openai/models"
 	"github.com/fabiustech/openai/objects"
@@ -41,13 +40,8 @@ type EmbeddingRequest struct {
 
 // CreateEmbeddings creates an embedding vector representing the input text.
 func (c *Client) CreateEmbeddings(ctx context.Context, request *EmbeddingRequest) (*EmbeddingResponse, error) {
-	var b, err = c.post(ctx, routes.Embeddings, request)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &EmbeddingResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.Embeddings, request, resp); err != nil {
 		return nil, err
 	}
 
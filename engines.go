@@ -2,7 +2,6 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 	"path"
 
 	"github.com/fabiustech/openai/routes"
@@ -19,16 +18,11 @@ type Engine struct {
 // ListEngines lists the currently available engines, and provides basic
 // information about each option such as the owner and availability.
 //
-// Deprecated: Please use their replacement, Models, instead.
+// Deprecated: The engines endpoint has been removed. Use ListModels and RetrieveModel instead.
 // https://beta.openai.com/docs/api-reference/models
 func (c *Client) ListEngines(ctx context.Context) (*List[*Engine], error) {
-	var b, err = c.get(ctx, routes.Engines)
-	if err != nil {
-		return nil, err
-	}
-
 	var el = &List[*Engine]{}
-	if err = json.Unmarshal(b, el); err != nil {
+	if err := c.get(ctx, routes.Engines, el); err != nil {
 		return nil, err
 	}
 
@@ -37,16 +31,11 @@ func (c *Client) ListEngines(ctx context.Context) (*List[*Engine], error) {
 
 // GetEngine retrieves a model instance, providing basic information about it such as the owner and availability.
 //
-// Deprecated: Please use their replacement, Models, instead.
+// Deprecated: The engines endpoint has been removed. Use ListModels and RetrieveModel instead.
 // https://beta.openai.com/docs/api-reference/models
 func (c *Client) GetEngine(ctx context.Context, id string) (*Engine, error) {
-	var b, err = c.get(ctx, path.Join(routes.Engines, id))
-	if err != nil {
-		return nil, err
-	}
-
 	var e = &Engine{}
-	if err = json.Unmarshal(b, e); err != nil {
+	if err := c.get(ctx, path.Join(routes.Engines, id), e); err != nil {
 		return nil, err
 	}
 
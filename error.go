@@ -16,11 +16,19 @@ type Error struct {
 	Message    string  `json:"message"`
 	Param      *string `json:"param,omitempty"`
 	Type       string  `json:"type"`
+	// RequestID is the value of the "x-request-id" response header, which OpenAI support can use to identify the
+	// request.
+	RequestID string `json:"requestID,omitempty"`
 }
 
 // Error implements the error interface.
 func (e *Error) Error() string {
-	return fmt.Sprintf("Code: %v, Message: %s, Type: %s, Param: %v", e.Code, e.Message, e.Type, e.Param)
+	var s = fmt.Sprintf("Code: %v, Message: %s, Type: %s, Param: %v", e.Code, e.Message, e.Type, e.Param)
+	if e.RequestID != "" {
+		s += ", RequestID: " + e.RequestID
+	}
+
+	return s
 }
 
 // Retryable returns true if the error is retryable.
@@ -2,7 +2,8 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
+	"io"
+	"mime/multipart"
 	"os"
 	"path"
 
@@ -21,6 +22,22 @@ type FileRequest struct {
 	Purpose string
 }
 
+// writeForm implements formWriter.
+func (fr *FileRequest) writeForm(w *multipart.Writer) error {
+	if err := w.WriteField("purposes", fr.Purpose); err != nil {
+		return err
+	}
+
+	var fw, err = w.CreateFormFile("file", fr.File.Name())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fw, fr.File)
+
+	return err
+}
+
 // NewFineTuneFileRequest returns a |*FileRequest| with File opened from |path| and Purpose set to "fine-tuned".
 func NewFineTuneFileRequest(path string) (*FileRequest, error) {
 	var f, err = os.Open(path)
@@ -46,13 +63,8 @@ type File struct {
 
 // ListFiles returns a list of files that belong to the user's organization.
 func (c *Client) ListFiles(ctx context.Context) (*List[*File], error) {
-	var b, err = c.get(ctx, routes.Files)
-	if err != nil {
-		return nil, err
-	}
-
 	var fl = &List[*File]{}
-	if err = json.Unmarshal(b, fl); err != nil {
+	if err := c.get(ctx, routes.Files, fl); err != nil {
 		return nil, err
 	}
 
@@ -62,13 +74,8 @@ func (c *Client) ListFiles(ctx context.Context) (*List[*File], error) {
 // UploadFile uploads a file that contains document(s) to be used across various endpoints/features. Currently, the size
 // of all the files uploaded by one organization can be up to 1 GB.
 func (c *Client) UploadFile(ctx context.Context, fr *FileRequest) (*File, error) {
-	var b, err = c.postFile(ctx, fr)
-	if err != nil {
-		return nil, err
-	}
-
 	var f = &File{}
-	if err = json.Unmarshal(b, f); err != nil {
+	if err := c.postFile(ctx, fr, f); err != nil {
 		return nil, err
 	}
 
@@ -77,20 +84,13 @@ func (c *Client) UploadFile(ctx context.Context, fr *FileRequest) (*File, error)
 
 // DeleteFile deletes a file.
 func (c *Client) DeleteFile(ctx context.Context, id string) error {
-	var _, err = c.delete(ctx, path.Join(routes.Files, id))
-
-	return err
+	return c.delete(ctx, path.Join(routes.Files, id), nil)
 }
 
 // RetrieveFile returns information about a specific file.
 func (c *Client) RetrieveFile(ctx context.Context, id string) (*File, error) {
-	var b, err = c.get(ctx, path.Join(routes.Files, id))
-	if err != nil {
-		return nil, err
-	}
-
 	var f = &File{}
-	if err = json.Unmarshal(b, f); err != nil {
+	if err := c.get(ctx, path.Join(routes.Files, id), f); err != nil {
 		return nil, err
 	}
 
@@ -2,7 +2,6 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 	"path"
 
 	"github.com/fabiustech/openai/models"
@@ -138,13 +137,8 @@ type FineTuneDeletionResponse struct {
 // CreateFineTune creates a job that fine-tunes a specified model from a given dataset. *FineTuneResponse includes
 // details of the enqueued job including job status and the name of the fine-tuned models once complete.
 func (c *Client) CreateFineTune(ctx context.Context, ftr *FineTuneRequest) (*FineTuneResponse, error) {
-	var b, err = c.post(ctx, routes.FineTunes, ftr)
-	if err != nil {
-		return nil, err
-	}
-
 	var f = &FineTuneResponse{}
-	if err = json.Unmarshal(b, f); err != nil {
+	if err := c.post(ctx, routes.FineTunes, ftr, f); err != nil {
 		return nil, err
 	}
 
@@ -153,13 +147,8 @@ func (c *Client) CreateFineTune(ctx context.Context, ftr *FineTuneRequest) (*Fin
 
 // ListFineTunes lists your organization's fine-tuning jobs.
 func (c *Client) ListFineTunes(ctx context.Context) (*List[*FineTuneResponse], error) {
-	var b, err = c.get(ctx, routes.FineTunes)
-	if err != nil {
-		return nil, err
-	}
-
 	var l = &List[*FineTuneResponse]{}
-	if err = json.Unmarshal(b, l); err != nil {
+	if err := c.get(ctx, routes.FineTunes, l); err != nil {
 		return nil, err
 	}
 
@@ -168,13 +157,8 @@ func (c *Client) ListFineTunes(ctx context.Context) (*List[*FineTuneResponse], e
 
 // RetrieveFineTune gets info about the fine-tune job.
 func (c *Client) RetrieveFineTune(ctx context.Context, id string) (*FineTuneResponse, error) {
-	var b, err = c.get(ctx, path.Join(routes.FineTunes, id))
-	if err != nil {
-		return nil, err
-	}
-
 	var f = &FineTuneResponse{}
-	if err = json.Unmarshal(b, f); err != nil {
+	if err := c.get(ctx, path.Join(routes.FineTunes, id), f); err != nil {
 		return nil, err
 	}
 
@@ -183,13 +167,8 @@ func (c *Client) RetrieveFineTune(ctx context.Context, id string) (*FineTuneResp
 
 // CancelFineTune immediately cancels a fine-tune job.
 func (c *Client) CancelFineTune(ctx context.Context, id string) (*FineTuneResponse, error) {
-	var b, err = c.post(ctx, path.Join(routes.FineTunes, id, "cancel"), nil)
-	if err != nil {
-		return nil, err
-	}
-
 	var f = &FineTuneResponse{}
-	if err = json.Unmarshal(b, f); err != nil {
+	if err := c.post(ctx, path.Join(routes.FineTunes, id, "cancel"), nil, f); err != nil {
 		return nil, err
 	}
 
@@ -199,13 +178,8 @@ func (c *Client) CancelFineTune(ctx context.Context, id string) (*FineTuneRespon
 // ListFineTuneEvents returns fine-grained status updates for a fine-tune job.
 // TODO: Support streaming (in a different method).
 func (c *Client) ListFineTuneEvents(ctx context.Context, id string) (*List[*Event], error) {
-	var b, err = c.get(ctx, path.Join(routes.FineTunes, id, "events"))
-	if err != nil {
-		return nil, err
-	}
-
 	var l = &List[*Event]{}
-	if err = json.Unmarshal(b, l); err != nil {
+	if err := c.get(ctx, path.Join(routes.FineTunes, id, "events"), l); err != nil {
 		return nil, err
 	}
 
@@ -214,13 +188,8 @@ func (c *Client) ListFineTuneEvents(ctx context.Context, id string) (*List[*Even
 
 // DeleteFineTune delete a fine-tuned model. You must have the Owner role in your organization.
 func (c *Client) DeleteFineTune(ctx context.Context, id string) (*FineTuneDeletionResponse, error) {
-	var b, err = c.delete(ctx, path.Join(routes.FineTunes, id))
-	if err != nil {
-		return nil, err
-	}
-
 	var f = &FineTuneDeletionResponse{}
-	if err = json.Unmarshal(b, f); err != nil {
+	if err := c.delete(ctx, path.Join(routes.FineTunes, id), f); err != nil {
 		return nil, err
 	}
 
@@ -2,7 +2,6 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/fabiustech/openai/images"
 	"github.com/fabiustech/openai/routes"
@@ -89,13 +88,8 @@ type ImageData struct {
 
 // CreateImage creates an image (or images) given a prompt.
 func (c *Client) CreateImage(ctx context.Context, ir *CreateImageRequest) (*ImageResponse, error) {
-	var b, err = c.post(ctx, routes.ImageGenerations, ir)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &ImageResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.ImageGenerations, ir, resp); err != nil {
 		return nil, err
 	}
 
@@ -104,13 +98,8 @@ func (c *Client) CreateImage(ctx context.Context, ir *CreateImageRequest) (*Imag
 
 // EditImage creates an edited or extended image (or images) given an original image and a prompt.
 func (c *Client) EditImage(ctx context.Context, eir *EditImageRequest) (*ImageResponse, error) {
-	var b, err = c.post(ctx, routes.ImageEdits, eir)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &ImageResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.ImageEdits, eir, resp); err != nil {
 		return nil, err
 	}
 
@@ -119,13 +108,8 @@ func (c *Client) EditImage(ctx context.Context, eir *EditImageRequest) (*ImageRe
 
 // ImageVariation creates a variation (or variations) of a given image.
 func (c *Client) ImageVariation(ctx context.Context, vir *VariationImageRequest) (*ImageResponse, error) {
-	var b, err = c.post(ctx, routes.ImageVariations, vir)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &ImageResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.ImageVariations, vir, resp); err != nil {
 		return nil, err
 	}
 
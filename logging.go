@@ -0,0 +1,242 @@
+//go:build go1.21
+
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// LogOptions configures the logging of API calls. See WithLogger.
+type LogOptions struct {
+	// Bodies specifies that the request and response headers and bodies are logged. Streamed and multipart bodies are
+	// not logged. The "Authorization", "api-key" and "OpenAI-Organization" headers are always redacted.
+	Bodies bool
+	// RedactContent specifies that message contents, prompts, inputs and function arguments are redacted from logged
+	// bodies.
+	RedactContent bool
+}
+
+// WithLogger configures the Client to log every API call to |l|: its route, model, latency, HTTP status, token usage
+// and request ID. Successful calls are logged at slog.LevelInfo, and failed calls at slog.LevelError. Streaming calls
+// are logged once the stream ends. A nil |opts| is equivalent to the zero LogOptions. See LoggingMiddleware.
+func WithLogger(l *slog.Logger, opts *LogOptions) Option {
+	return WithMiddleware(LoggingMiddleware(l, opts))
+}
+
+// LoggingMiddleware returns Middleware which logs every API call to |l|. See WithLogger.
+func LoggingMiddleware(l *slog.Logger, opts *LogOptions) Middleware {
+	if opts == nil {
+		opts = &LogOptions{}
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			var start = time.Now()
+			var res, err = next(ctx, op)
+			if err != nil {
+				logOperation(ctx, l, opts, op, nil, nil, start, err)
+				return nil, err
+			}
+			if res == nil || (op.Stream && res.Response == nil) {
+				// The Client fails the call with errNoResult.
+				logOperation(ctx, l, opts, op, nil, nil, start, errNoResult)
+				return res, nil
+			}
+
+			if !op.Stream {
+				var u struct {
+					Usage *Usage `json:"usage"`
+				}
+				_ = json.Unmarshal(res.Body, &u)
+				logOperation(ctx, l, opts, op, res, u.Usage, start, nil)
+				return res, nil
+			}
+
+			var usage *Usage
+			var prev = res.OnEvent
+			res.OnEvent = func(event any, err error) {
+				if ur, ok := event.(usageReporter); ok && ur.usage() != nil {
+					usage = ur.usage()
+				}
+				if event == nil {
+					logOperation(ctx, l, opts, op, res, usage, start, err)
+				}
+				if prev != nil {
+					prev(event, err)
+				}
+			}
+
+			return res, nil
+		}
+	}
+}
+
+// logOperation logs the outcome of |op|. |res| is nil if |op| failed before a response was received.
+func logOperation(ctx context.Context, l *slog.Logger, opts *LogOptions, op *Operation, res *OperationResult,
+	usage *Usage, start time.Time, err error) {
+	var level = slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	var attrs = []slog.Attr{
+		slog.String("method", op.Method),
+		slog.String("route", op.Route),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if model := op.Model(); model != "" {
+		attrs = append(attrs, slog.String("model", model))
+	}
+	if op.Stream {
+		attrs = append(attrs, slog.Bool("stream", true))
+	}
+
+	var resp *http.Response
+	if res != nil {
+		resp = res.Response
+	}
+	var apiErr *Error
+	switch {
+	case resp != nil:
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if id := resp.Header.Get(headerRequestID); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+	case errors.As(err, &apiErr):
+		attrs = append(attrs, slog.Int("status", apiErr.StatusCode))
+		if apiErr.RequestID != "" {
+			attrs = append(attrs, slog.String("request_id", apiErr.RequestID))
+		}
+	}
+
+	if usage != nil {
+		attrs = append(attrs, slog.Group("usage",
+			slog.Int("prompt_tokens", usage.PromptTokens),
+			slog.Int("completion_tokens", usage.CompletionTokens),
+			slog.Int("total_tokens", usage.TotalTokens),
+		))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	if opts.Bodies {
+		attrs = append(attrs, bodyAttrs(opts, op, resp, res)...)
+	}
+
+	var msg = "openai request"
+	if err != nil {
+		msg = "openai request failed"
+	}
+	l.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// bodyAttrs returns the attributes which log the headers and bodies of |op|, redacted according to |opts|.
+func bodyAttrs(opts *LogOptions, op *Operation, resp *http.Response, res *OperationResult) []slog.Attr {
+	var attrs []slog.Attr
+
+	var reqAttrs []any
+	if resp != nil && resp.Request != nil {
+		reqAttrs = append(reqAttrs, slog.Any("header", redactHeader(resp.Request.Header)))
+	}
+	if op.Request != nil && !op.Multipart() {
+		if b, err := json.Marshal(op.Request); err == nil {
+			reqAttrs = append(reqAttrs, slog.String("body", string(redactBody(opts, b))))
+		}
+	}
+	if len(reqAttrs) > 0 {
+		attrs = append(attrs, slog.Group("request", reqAttrs...))
+	}
+
+	var respAttrs []any
+	if resp != nil {
+		respAttrs = append(respAttrs, slog.Any("header", redactHeader(resp.Header)))
+	}
+	if res != nil && !op.Stream && len(res.Body) > 0 {
+		respAttrs = append(respAttrs, slog.String("body", string(redactBody(opts, res.Body))))
+	}
+	if len(respAttrs) > 0 {
+		attrs = append(attrs, slog.Group("response", respAttrs...))
+	}
+
+	return attrs
+}
+
+// redacted replaces secrets and, optionally, content in logs.
+const redacted = "[REDACTED]"
+
+// secretHeaders are the headers which are always redacted from logs.
+var secretHeaders = []string{"Authorization", "Api-Key", "Openai-Organization"}
+
+func redactHeader(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+
+	h = h.Clone()
+	for _, k := range secretHeaders {
+		if _, ok := h[k]; ok {
+			h[k] = []string{redacted}
+		}
+	}
+
+	return h
+}
+
+// contentKeys are the JSON keys of request and response fields containing content, redacted with
+// LogOptions.RedactContent.
+var contentKeys = map[string]bool{
+	"content":     true,
+	"prompt":      true,
+	"input":       true,
+	"instruction": true,
+	"text":        true,
+	"arguments":   true,
+}
+
+// redactBody redacts the content of the JSON encoded body |b| if required by |opts|. Bodies which are not valid JSON
+// are redacted entirely.
+func redactBody(opts *LogOptions, b []byte) []byte {
+	if !opts.RedactContent {
+		return b
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return []byte(redacted)
+	}
+
+	var out, err = json.Marshal(redactValue(v))
+	if err != nil {
+		return []byte(redacted)
+	}
+
+	return out
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, vv := range t {
+			if contentKeys[k] {
+				t[k] = redacted
+			} else {
+				t[k] = redactValue(vv)
+			}
+		}
+	case []any:
+		for i, vv := range t {
+			t[i] = redactValue(vv)
+		}
+	}
+
+	return v
+}
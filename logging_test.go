@@ -0,0 +1,106 @@
+//go:build go1.21
+
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+)
+
+func TestWithLogger(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_123")
+		if r.URL.Path == "/v1/models/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"The model does not exist","type":"invalid_request_error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"secret answer"}}],` +
+			`"usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	var l = slog.New(slog.NewJSONHandler(&buf, nil))
+	var client = NewClientWithOrg(testToken, "org-123", WithBaseURL(ts.URL+"/v1"),
+		WithLogger(l, &LogOptions{Bodies: true, RedactContent: true}))
+
+	var _, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "secret question"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+
+	var _, getErr = client.RetrieveModel(context.Background(), "missing")
+	var apiErr *Error
+	if !errors.As(getErr, &apiErr) {
+		t.Fatalf("expected *Error, got %v", getErr)
+	}
+
+	var lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log records, got %d:\n%s", len(lines), buf.String())
+	}
+	for _, secret := range []string{testToken, "org-123", "secret question", "secret answer"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("expected %q to be redacted from logs:\n%s", secret, buf.String())
+		}
+	}
+
+	var rec struct {
+		Level     string `json:"level"`
+		Route     string `json:"route"`
+		Model     string `json:"model"`
+		Status    int    `json:"status"`
+		RequestID string `json:"request_id"`
+		Usage     *Usage `json:"usage"`
+	}
+	if err = json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("invalid log record: %v", err)
+	}
+	if rec.Level != "INFO" || rec.Route != "chat/completions" || rec.Model != "gpt-4o" || rec.Status != 200 ||
+		rec.RequestID != "req_123" || rec.Usage == nil || rec.Usage.TotalTokens != 10 {
+		t.Errorf("unexpected log record: %s", lines[0])
+	}
+
+	rec.Usage = nil
+	if err = json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatalf("invalid log record: %v", err)
+	}
+	if rec.Level != "ERROR" || rec.Status != 404 || rec.RequestID != "req_123" {
+		t.Errorf("unexpected log record: %s", lines[1])
+	}
+}
+
+func TestWithLoggerNoResult(t *testing.T) {
+	var empty = func(Handler) Handler {
+		return func(context.Context, *Operation) (*OperationResult, error) { return nil, nil }
+	}
+
+	var buf bytes.Buffer
+	var client = NewClient(testToken, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil)), nil),
+		WithMiddleware(empty))
+
+	if _, err := client.ListModels(context.Background()); !errors.Is(err, errNoResult) {
+		t.Fatalf("expected errNoResult, got %v", err)
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a log record, got %q", buf.String())
+	}
+	if record["level"] != "ERROR" {
+		t.Errorf("expected the call to be logged as failed, got %v", record)
+	}
+}
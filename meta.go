@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// headerRequestID is the response header containing the unique ID of the request.
+const headerRequestID = "x-request-id"
+
+// ResponseMeta holds metadata from the HTTP response to a request. Use ContextWithResponseMeta to obtain it.
+type ResponseMeta struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// RequestID is the value of the "x-request-id" header, which OpenAI support can use to identify the request.
+	RequestID string
+	// Organization is the value of the "openai-organization" header: the organization which the request was
+	// attributed to.
+	Organization string
+	// Version is the value of the "openai-version" header: the version of the API which served the request.
+	Version string
+	// ProcessingTime is the value of the "openai-processing-ms" header: the time the API spent processing the request.
+	ProcessingTime time.Duration
+	// RateLimit is the state of the rate limits of the request's model.
+	RateLimit RateLimitStatus
+	// Header contains all response headers.
+	Header http.Header
+}
+
+// RateLimitStatus is the state of a model's rate limits, as reported by the "x-ratelimit-*" response headers. Fields
+// are zero if the corresponding header was not sent.
+type RateLimitStatus struct {
+	// LimitRequests is the maximum number of requests permitted before exhausting the rate limit.
+	LimitRequests int
+	// LimitTokens is the maximum number of tokens permitted before exhausting the rate limit.
+	LimitTokens int
+	// RemainingRequests is the number of requests remaining before exhausting the rate limit.
+	RemainingRequests int
+	// RemainingTokens is the number of tokens remaining before exhausting the rate limit.
+	RemainingTokens int
+	// ResetRequests is the time until the request rate limit resets to its initial state.
+	ResetRequests time.Duration
+	// ResetTokens is the time until the token rate limit resets to its initial state.
+	ResetTokens time.Duration
+}
+
+type responseMetaKey struct{}
+
+// ContextWithResponseMeta returns a copy of |ctx| which captures the metadata of responses to requests made with it:
+// once a request returns (successfully or with an *Error), |m| holds the metadata of its final response. |m| is
+// overwritten by each request, so a separate context should be used for concurrent requests.
+func ContextWithResponseMeta(ctx context.Context, m *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, m)
+}
+
+// captureResponseMeta stores the metadata of |resp| in the *ResponseMeta of |ctx|, if any.
+func captureResponseMeta(ctx context.Context, resp *http.Response) {
+	var m, ok = ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	if !ok || m == nil {
+		return
+	}
+
+	var h = resp.Header
+	*m = ResponseMeta{
+		StatusCode:   resp.StatusCode,
+		RequestID:    h.Get(headerRequestID),
+		Organization: h.Get("openai-organization"),
+		Version:      h.Get("openai-version"),
+		RateLimit: RateLimitStatus{
+			LimitRequests:     headerInt(h, "x-ratelimit-limit-requests"),
+			LimitTokens:       headerInt(h, "x-ratelimit-limit-tokens"),
+			RemainingRequests: headerInt(h, "x-ratelimit-remaining-requests"),
+			RemainingTokens:   headerInt(h, "x-ratelimit-remaining-tokens"),
+			ResetRequests:     headerDuration(h, "x-ratelimit-reset-requests"),
+			ResetTokens:       headerDuration(h, "x-ratelimit-reset-tokens"),
+		},
+		Header: h.Clone(),
+	}
+	if ms, err := strconv.ParseFloat(h.Get("openai-processing-ms"), 64); err == nil {
+		m.ProcessingTime = time.Duration(ms * float64(time.Millisecond))
+	}
+}
+
+func headerInt(h http.Header, key string) int {
+	var n, _ = strconv.Atoi(h.Get(key))
+	return n
+}
+
+func headerDuration(h http.Header, key string) time.Duration {
+	var d, _ = time.ParseDuration(h.Get(key))
+	return d
+}
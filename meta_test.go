@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseMeta(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_123")
+		w.Header().Set("openai-processing-ms", "250")
+		w.Header().Set("openai-organization", "acme")
+		w.Header().Set("x-ratelimit-limit-tokens", "150000")
+		w.Header().Set("x-ratelimit-remaining-tokens", "149984")
+		w.Header().Set("x-ratelimit-reset-tokens", "6ms")
+
+		if r.URL.Path == "/v1/models/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"The model does not exist","type":"invalid_request_error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"gpt-4o","object":"model"}`))
+	}))
+	defer ts.Close()
+
+	var client, _ = newTestClient(ts.URL)
+
+	var meta ResponseMeta
+	var ctx = ContextWithResponseMeta(context.Background(), &meta)
+	if _, err := client.RetrieveModel(ctx, "gpt-4o"); err != nil {
+		t.Fatalf("RetrieveModel error: %v", err)
+	}
+
+	if meta.StatusCode != http.StatusOK || meta.RequestID != "req_123" || meta.Organization != "acme" {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+	if meta.ProcessingTime != 250*time.Millisecond {
+		t.Errorf("expected processing time of 250ms, got %v", meta.ProcessingTime)
+	}
+	var want = RateLimitStatus{LimitTokens: 150000, RemainingTokens: 149984, ResetTokens: 6 * time.Millisecond}
+	if meta.RateLimit != want {
+		t.Errorf("expected rate limit %+v, got %+v", want, meta.RateLimit)
+	}
+
+	var _, err = client.RetrieveModel(ctx, "missing")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	if apiErr.RequestID != "req_123" {
+		t.Errorf("expected request ID on error, got %q", apiErr.RequestID)
+	}
+	if meta.StatusCode != http.StatusNotFound {
+		t.Errorf("expected meta of failed request, got status %d", meta.StatusCode)
+	}
+}
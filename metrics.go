@@ -0,0 +1,152 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/fabiustech/openai/routes"
+)
+
+// Metrics records measurements of API calls, e.g. as counters and histograms. Implementations must be safe for
+// concurrent use. See PrometheusMetrics.
+type Metrics interface {
+	// ObserveCall records the outcome of a single API call. For streaming calls, it is called once the stream ends.
+	ObserveCall(m *CallMetrics)
+}
+
+// CallMetrics holds the measurements of a single API call.
+type CallMetrics struct {
+	// Model is the ID of the model which the request is for, or "" if it has none.
+	Model string
+	// Route is the route of the endpoint, with any ID replaced by "{id}" (e.g. "files/{id}"), so that it can be used as
+	// a low-cardinality label.
+	Route string
+	// Stream specifies that the response was streamed.
+	Stream bool
+	// StatusCode is the HTTP status code of the response, or 0 if none was received.
+	StatusCode int
+	// Err is the error which failed the call, if any. For streaming calls, this includes errors reading the stream.
+	Err error
+	// ErrorType is the Type of Err if it is an *Error (or its status code if it has none), "timeout" if the context was
+	// cancelled or timed out, and "_OTHER" for any other error. It is "" if the call succeeded.
+	ErrorType string
+	// ErrorCode is the Code of Err if it is an *Error.
+	ErrorCode string
+	// Latency is the time taken to receive the response: the full body, or for streaming calls, its headers.
+	Latency time.Duration
+	// TimeToFirstEvent is the time taken to receive the first event of a stream, or 0 if none was received.
+	TimeToFirstEvent time.Duration
+	// StreamDuration is the time taken to receive the whole stream, including Latency. Not set for calls which don't
+	// stream.
+	StreamDuration time.Duration
+	// Usage is the token usage reported by the response, if any. Streaming responses only report usage if
+	// StreamOptions.IncludeUsage is set, or the Client has a Budget or RateLimiter.
+	Usage *Usage
+}
+
+// WithMetrics configures the Client to record the measurements of every API call with |m|. See MetricsMiddleware.
+func WithMetrics(m Metrics) Option {
+	return WithMiddleware(MetricsMiddleware(m))
+}
+
+// MetricsMiddleware returns Middleware which records the measurements of every API call with |m|. See WithMetrics.
+func MetricsMiddleware(m Metrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			var cm = &CallMetrics{
+				Model:  op.Model(),
+				Route:  routeTemplate(op.Route),
+				Stream: op.Stream,
+			}
+
+			var start = time.Now()
+			var res, err = next(ctx, op)
+			cm.Latency = time.Since(start)
+			if err != nil {
+				observeCall(m, cm, err)
+				return nil, err
+			}
+			if res == nil || (op.Stream && res.Response == nil) {
+				// The Client fails the call with errNoResult.
+				observeCall(m, cm, errNoResult)
+				return res, nil
+			}
+			if res.Response != nil {
+				cm.StatusCode = res.Response.StatusCode
+			}
+
+			if !op.Stream {
+				var u struct {
+					Usage *Usage `json:"usage"`
+				}
+				_ = json.Unmarshal(res.Body, &u)
+				cm.Usage = u.Usage
+				observeCall(m, cm, nil)
+				return res, nil
+			}
+
+			var prev = res.OnEvent
+			res.OnEvent = func(event any, err error) {
+				if event != nil {
+					if cm.TimeToFirstEvent == 0 {
+						cm.TimeToFirstEvent = time.Since(start)
+					}
+					if ur, ok := event.(usageReporter); ok && ur.usage() != nil {
+						cm.Usage = ur.usage()
+					}
+				} else {
+					cm.StreamDuration = time.Since(start)
+					observeCall(m, cm, err)
+				}
+				if prev != nil {
+					prev(event, err)
+				}
+			}
+
+			return res, nil
+		}
+	}
+}
+
+// observeCall records |cm|, which failed with |err|, if non-nil, with |m|.
+func observeCall(m Metrics, cm *CallMetrics, err error) {
+	if err != nil {
+		cm.Err, cm.ErrorType = err, errorType(err)
+
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			cm.ErrorCode = apiErr.Code
+			if cm.StatusCode == 0 {
+				cm.StatusCode = apiErr.StatusCode
+			}
+		}
+	}
+
+	m.ObserveCall(cm)
+}
+
+// nestedRoutes are the routes which contain a "/" but no ID.
+var nestedRoutes = map[string]bool{
+	routes.ChatCompletions:     true,
+	routes.ImageGenerations:    true,
+	routes.ImageEdits:          true,
+	routes.ImageVariations:     true,
+	routes.AudioTranscriptions: true,
+}
+
+// routeTemplate returns |route| with the ID of any resource it refers to replaced by "{id}", e.g. "files/{id}".
+func routeTemplate(route string) string {
+	if nestedRoutes[route] {
+		return route
+	}
+
+	var parts = strings.Split(route, "/")
+	if len(parts) > 1 {
+		parts[1] = "{id}"
+	}
+
+	return strings.Join(parts, "/")
+}
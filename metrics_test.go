@@ -0,0 +1,135 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			if r.Header.Get("Accept") == "text/event-stream; charset=utf-8" {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = fmt.Fprint(w, `data: {"id":"2","choices":[{"index":0,"delta":{"content":"a"}}]}`+"\n\n")
+				_, _ = fmt.Fprint(w, `data: {"id":"2","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`+"\n\n")
+				_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"1","choices":[],"usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"no such model","type":"invalid_request_error","code":"model_not_found"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	var metrics = &PrometheusMetrics{Buckets: []float64{60, 1}}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithMetrics(metrics))
+	var req = &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+	}
+
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+
+	var chunks, errs, err = client.CreateStreamingChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateStreamingChatCompletion error: %v", err)
+	}
+	for range chunks {
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if _, err = client.RetrieveModel(context.Background(), "ft:gpt-4o:org"); err == nil {
+		t.Fatal("expected RetrieveModel to fail")
+	}
+
+	var srv = httptest.NewServer(metrics)
+	defer srv.Close()
+
+	var resp *http.Response
+	if resp, err = http.Get(srv.URL); err != nil {
+		t.Fatalf("GET metrics error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected Content-Type: %s", ct)
+	}
+
+	var b []byte
+	if b, err = io.ReadAll(resp.Body); err != nil {
+		t.Fatalf("reading metrics error: %v", err)
+	}
+	var out = string(b)
+
+	for _, line := range []string{
+		"# TYPE openai_requests_total counter",
+		`openai_requests_total{model="gpt-4o",route="chat/completions",status="200"} 2`,
+		`openai_requests_total{model="",route="models/{id}",status="404"} 1`,
+		`openai_errors_total{model="",route="models/{id}",type="invalid_request_error",code="model_not_found"} 1`,
+		"# TYPE openai_request_duration_seconds histogram",
+		`openai_request_duration_seconds_bucket{model="gpt-4o",route="chat/completions",le="1"} 2`,
+		`openai_request_duration_seconds_bucket{model="gpt-4o",route="chat/completions",le="60"} 2`,
+		`openai_request_duration_seconds_bucket{model="gpt-4o",route="chat/completions",le="+Inf"} 2`,
+		`openai_request_duration_seconds_count{model="gpt-4o",route="chat/completions"} 2`,
+		`openai_tokens_total{model="gpt-4o",route="chat/completions",type="input"} 11`,
+		`openai_tokens_total{model="gpt-4o",route="chat/completions",type="output"} 6`,
+		`openai_stream_duration_seconds_count{model="gpt-4o",route="chat/completions"} 1`,
+		`openai_stream_time_to_first_event_seconds_count{model="gpt-4o",route="chat/completions"} 1`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, out)
+		}
+	}
+}
+
+func TestRouteTemplate(t *testing.T) {
+	for route, want := range map[string]string{
+		"chat/completions":            "chat/completions",
+		"images/generations":          "images/generations",
+		"models":                      "models",
+		"files/file-abc":              "files/{id}",
+		"fines-tunes/ft-abc/events":   "fines-tunes/{id}/events",
+		"models/ft:gpt-4o:org:suffix": "models/{id}",
+	} {
+		if got := routeTemplate(route); got != want {
+			t.Errorf("routeTemplate(%q) = %q, want %q", route, got, want)
+		}
+	}
+}
+
+type metricsFunc func(m *CallMetrics)
+
+func (f metricsFunc) ObserveCall(m *CallMetrics) { f(m) }
+
+func TestMetricsNoResult(t *testing.T) {
+	var empty = func(Handler) Handler {
+		return func(context.Context, *Operation) (*OperationResult, error) { return nil, nil }
+	}
+
+	var observed []*CallMetrics
+	var client = NewClient(testToken, WithMetrics(metricsFunc(func(m *CallMetrics) {
+		observed = append(observed, m)
+	})), WithMiddleware(empty))
+
+	if _, err := client.ListModels(context.Background()); !errors.Is(err, errNoResult) {
+		t.Fatalf("expected errNoResult, got %v", err)
+	}
+	if len(observed) != 1 || !errors.Is(observed[0].Err, errNoResult) || observed[0].ErrorType != "_OTHER" {
+		t.Errorf("expected the call to be observed as failed, got %+v", observed)
+	}
+}
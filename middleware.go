@@ -0,0 +1,166 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+
+	"github.com/fabiustech/openai/models"
+)
+
+// Operation describes a single call to the API, as seen by Middleware.
+type Operation struct {
+	// Method is the HTTP method, e.g. http.MethodPost.
+	Method string
+	// Route is the route of the endpoint, relative to the base URL, e.g. routes.ChatCompletions.
+	Route string
+	// Request is the typed request, e.g. a *ChatCompletionRequest, or nil for requests without a body. Middleware may
+	// modify it before calling the next Handler.
+	Request any
+	// Stream specifies that the response is a stream of server-sent events.
+	Stream bool
+	// Header contains additional headers to send with the request. They take precedence over all other headers,
+	// including "Authorization".
+	Header http.Header
+}
+
+// formWriter is implemented by requests which are sent as multipart/form-data.
+type formWriter interface {
+	writeForm(w *multipart.Writer) error
+}
+
+// Multipart reports whether the request is sent as multipart/form-data, e.g. file and audio uploads.
+func (op *Operation) Multipart() bool {
+	var _, ok = op.Request.(formWriter)
+
+	return ok
+}
+
+// Model returns the ID of the model which the Operation's Request is for, or "" if it has none.
+func (op *Operation) Model() string {
+	var v = reflect.ValueOf(op.Request)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	var f = v.FieldByName("Model")
+	for f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return ""
+		}
+		f = f.Elem()
+	}
+	if !f.IsValid() || !f.CanInterface() {
+		return ""
+	}
+
+	return fmt.Sprint(f.Interface())
+}
+
+// OperationResult is the outcome of an Operation.
+type OperationResult struct {
+	// Response is the raw HTTP response. For streaming Operations, events are read from its Body once the Middleware
+	// chain returns; otherwise, its Body has already been read into Body and closed.
+	Response *http.Response
+	// Body is the raw response body. Not set for streaming Operations.
+	Body []byte
+	// Value is the decoded response, e.g. a *ChatCompletionResponse. Not set for streaming Operations. Middleware which
+	// returns an OperationResult without calling the next Handler (e.g. a mock) need only set Body, which the Client decodes.
+	Value any
+	// OnEvent, if set, is called for streaming Operations with each decoded event (e.g. a *ChatCompletionChunk) as it
+	// is received, and then once the stream ends, with a nil event and the error which ended it (nil if the stream
+	// completed). It is called from the goroutine reading the stream, before the events are sent to the caller.
+	// Middleware which observes streams should wrap any existing OnEvent.
+	OnEvent func(event any, err error)
+
+	// record, if set, is called with the Usage of streamed events, or with nil if the stream ends without one.
+	record func(*Usage)
+	// hideUsage specifies that the Client requested the final chunk reporting the stream's Usage, so it is not sent to
+	// the caller.
+	hideUsage bool
+}
+
+// Handler performs an Operation.
+type Handler func(ctx context.Context, op *Operation) (*OperationResult, error)
+
+// Middleware wraps a Handler, e.g. to log, modify or mock Operations. It is applied uniformly to every API call,
+// including multipart and streaming calls.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware configures the Client to pass every API call through |mw|. The first Middleware is the outermost,
+// i.e. it sees each Operation first and each OperationResult last. Multiple calls append.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// errNoResult is returned if Middleware returns neither an OperationResult nor an error.
+var errNoResult = errors.New("openai: middleware returned no result")
+
+// call passes |op| through the Client's Middleware and performs it. Unless |op| streams, the response is decoded into
+// |out|, if non-nil.
+func (c *Client) call(ctx context.Context, op *Operation, out any) (*OperationResult, error) {
+	var h Handler = func(ctx context.Context, op *Operation) (*OperationResult, error) {
+		return c.do(ctx, op, out)
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+
+	var res, err = h(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || (op.Stream && res.Response == nil) {
+		return nil, errNoResult
+	}
+
+	if !op.Stream && out != nil && res.Value != out {
+		if err = decode(res.Body, out); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// streamingPayload returns the JSON payload which requests that the response to |req| be streamed. If |usage|, the
+// response is also requested to end with a chunk reporting its Usage, and the returned bool reports whether |req|
+// didn't already request one.
+func streamingPayload(req any, usage bool) (any, bool) {
+	switch r := req.(type) {
+	case *ChatCompletionRequest:
+		var s = &streamingChatCompletion{Stream: true, ChatCompletionRequest: r, StreamOptions: r.StreamOptions}
+		if !usage || (r.StreamOptions != nil && r.StreamOptions.IncludeUsage) {
+			return s, false
+		}
+
+		var opts StreamOptions
+		if r.StreamOptions != nil {
+			opts = *r.StreamOptions
+		}
+		opts.IncludeUsage = true
+		s.StreamOptions = &opts
+
+		return s, true
+	case *CompletionRequest[models.Completion]:
+		var s = &streamingCompletion{Stream: true, CompletionRequest: r}
+		if usage {
+			s.StreamOptions = &StreamOptions{IncludeUsage: true}
+		}
+
+		return s, usage
+	default:
+		return req, false
+	}
+}
@@ -0,0 +1,168 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/routes"
+)
+
+func TestMiddleware(t *testing.T) {
+	var auth, purpose string
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}]}`))
+		case "/v1/files":
+			purpose = r.FormValue("purposes")
+			_, _ = w.Write([]byte(`{"id":"file-1","object":"file"}`))
+		default:
+			http.Error(w, "the resource path doesn't exist", http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	var ops []string
+	var logging = func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			var res, err = next(ctx, op)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, fmt.Sprintf("%s %s %T multipart=%t -> %d %T", op.Method, op.Route, op.Request,
+				op.Multipart(), res.Response.StatusCode, res.Value))
+			return res, nil
+		}
+	}
+	var refresh = func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			op.Header = http.Header{"Authorization": []string{"Bearer refreshed"}}
+			return next(ctx, op)
+		}
+	}
+
+	var rewrite = func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			if fr, ok := op.Request.(*FileRequest); ok {
+				op.Request = &FileRequest{File: fr.File, Purpose: "assistants"}
+			}
+			return next(ctx, op)
+		}
+	}
+
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithMiddleware(logging, refresh, rewrite))
+
+	var resp, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "hi" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if auth != "Bearer refreshed" {
+		t.Errorf("expected middleware headers to take precedence, got %q", auth)
+	}
+
+	var f, _ = os.Create(filepath.Join(t.TempDir(), "data.jsonl"))
+	defer f.Close()
+	if _, err = client.UploadFile(context.Background(), &FileRequest{File: f, Purpose: "fine-tune"}); err != nil {
+		t.Fatalf("UploadFile error: %v", err)
+	}
+	if purpose != "assistants" {
+		t.Errorf("expected the multipart body to be built from the modified request, got purpose %q", purpose)
+	}
+
+	var want = []string{
+		"POST chat/completions *openai.ChatCompletionRequest multipart=false -> 200 *openai.ChatCompletionResponse",
+		"POST files *openai.FileRequest multipart=true -> 200 *openai.File",
+	}
+	if strings.Join(ops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected operations:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(ops, "\n"))
+	}
+}
+
+func TestMiddlewareMock(t *testing.T) {
+	var mock = func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			switch {
+			case op.Route == routes.Models:
+				return &OperationResult{Body: []byte(`{"object":"list","data":[{"id":"gpt-4o"}]}`)}, nil
+			case op.Stream:
+				var body = "data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"a\"}}]}\n\n" +
+					"data: {\"id\":\"1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"b\"}}]}\n\ndata: [DONE]\n\n"
+				return &OperationResult{Response: &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}}, nil
+			default:
+				return next(ctx, op)
+			}
+		}
+	}
+
+	var events int
+	var done bool
+	var observe = func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			var res, err = next(ctx, op)
+			if err != nil || !op.Stream {
+				return res, err
+			}
+			var prev = res.OnEvent
+			res.OnEvent = func(event any, err error) {
+				if event != nil {
+					events++
+				} else {
+					done = err == nil
+				}
+				if prev != nil {
+					prev(event, err)
+				}
+			}
+			return res, nil
+		}
+	}
+
+	var hc = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("unexpected request to %s", r.URL)
+	})}
+	var client = NewClient(testToken, WithHTTPClient(hc), WithMiddleware(observe, mock))
+
+	var l, err = client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	if len(l.Data) != 1 || l.Data[0].ID != "gpt-4o" {
+		t.Errorf("unexpected models: %+v", l.Data)
+	}
+
+	var chunks, errs, _ = client.CreateStreamingChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+	})
+	var acc = &ChatCompletionAccumulator{}
+	for chunk := range chunks {
+		acc.Add(chunk)
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+	if got := acc.Response().Choices[0].Message.Content; got != "ab" {
+		t.Errorf("expected streamed content %q, got %q", "ab", got)
+	}
+	if events != 2 || !done {
+		t.Errorf("expected 2 events and completion to be observed, got %d events, done=%t", events, done)
+	}
+}
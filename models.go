@@ -0,0 +1,126 @@
+package openai
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/objects"
+	"github.com/fabiustech/openai/routes"
+)
+
+// Model contains all relevant fields for requests to the models endpoint.
+type Model struct {
+	// ID is the model identifier, which can be referenced in the API endpoints.
+	ID string `json:"id"`
+	// Object is always "model".
+	Object objects.Object `json:"object"`
+	// Created is the Unix timestamp (in seconds) when the model was created.
+	Created uint64 `json:"created"`
+	// OwnedBy is the organization that owns the model, e.g. "openai", "system" or the ID of the organization which
+	// fine-tuned it.
+	OwnedBy string `json:"owned_by"`
+}
+
+// FineTuned reports whether the model is a fine-tuned model, which can be deleted with DeleteModel.
+func (m *Model) FineTuned() bool {
+	return strings.HasPrefix(m.ID, "ft:") || strings.Contains(m.ID, ":ft-")
+}
+
+// ModelDeletionResponse is the response from the models/delete endpoint.
+type ModelDeletionResponse struct {
+	ID      string         `json:"id"`
+	Object  objects.Object `json:"object"`
+	Deleted bool           `json:"deleted"`
+}
+
+// ListModels lists the currently available models, and provides basic information about each one such as the owner
+// and availability.
+// https://platform.openai.com/docs/api-reference/models/list
+func (c *Client) ListModels(ctx context.Context) (*List[*Model], error) {
+	var l = &List[*Model]{}
+	if err := c.get(ctx, routes.Models, l); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// RetrieveModel retrieves the model whose ID is |id|, providing basic information about it such as the owner.
+// https://platform.openai.com/docs/api-reference/models/retrieve
+func (c *Client) RetrieveModel(ctx context.Context, id string) (*Model, error) {
+	var m = &Model{}
+	if err := c.get(ctx, path.Join(routes.Models, id), m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// DeleteModel deletes the fine-tuned model whose ID is |id|. You must have the Owner role in your organization to
+// delete a model.
+// https://platform.openai.com/docs/api-reference/models/delete
+func (c *Client) DeleteModel(ctx context.Context, id string) (*ModelDeletionResponse, error) {
+	var d = &ModelDeletionResponse{}
+	if err := c.delete(ctx, path.Join(routes.Models, id), d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ModelReconciliation compares the models available to an API key with the predefined constants of the models
+// package. All IDs are sorted.
+type ModelReconciliation struct {
+	// Unknown contains the IDs of available models which have no predefined constant. They can still be used with
+	// models.NewChatCompletion and friends.
+	Unknown []string
+	// FineTuned contains the IDs of available fine-tuned models.
+	FineTuned []string
+	// Deprecated contains the IDs of available models which have been deprecated and will be shut down.
+	Deprecated []string
+	// Retired contains the IDs of predefined constants which are no longer available, either because they have been
+	// shut down or because the API key does not have access to them.
+	Retired []string
+}
+
+// ReconcileModels compares |available|, as returned by ListModels, with the predefined constants of the models
+// package, reporting unknown, fine-tuned, deprecated and retired model IDs.
+func ReconcileModels(available []*Model) *ModelReconciliation {
+	var r = &ModelReconciliation{}
+	var live = make(map[string]bool, len(available))
+	for _, m := range available {
+		live[m.ID] = true
+	}
+
+	var known = make(map[string]bool)
+	for _, id := range models.IDs() {
+		known[id] = true
+		if !live[id] {
+			r.Retired = append(r.Retired, id)
+		}
+	}
+
+	var now = time.Now()
+	for _, m := range available {
+		switch {
+		case m.FineTuned():
+			r.FineTuned = append(r.FineTuned, m.ID)
+		case !known[m.ID]:
+			r.Unknown = append(r.Unknown, m.ID)
+		default:
+			if caps, ok := models.Lookup(m.ID); ok && caps.Deprecated() && !caps.ShutDown(now) {
+				r.Deprecated = append(r.Deprecated, m.ID)
+			}
+		}
+	}
+
+	sort.Strings(r.Unknown)
+	sort.Strings(r.FineTuned)
+	sort.Strings(r.Deprecated)
+
+	return r
+}
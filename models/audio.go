@@ -10,7 +10,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (a Audio) String() string {
-	return audioToString[a]
+	return audioModels.id(a)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -19,24 +19,23 @@ func (a Audio) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewAudio.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (a *Audio) UnmarshalText(b []byte) error {
-	if val, ok := stringToAudio[(string(b))]; ok {
-		*a = val
-		return nil
-	}
+	var err error
+	*a, err = audioModels.decode(string(b))
 
-	*a = UnknownAudioModel
+	return err
+}
 
-	return nil
+// NewAudio returns the Audio whose ID is |id|. If |id| is not one of the predefined constants (e.g. it is the ID of a
+// fine-tuned model, an Azure deployment or a newly released model), a new value is returned which marshals back to
+// |id|. See the package documentation for the limits of such values.
+func NewAudio(id string) Audio {
+	return audioModels.intern(id)
 }
 
-var audioToString = map[Audio]string{
+var audioModels = newRegistry(map[Audio]string{
 	Whisper1: "whisper-1",
 	Whisper2: "whisper-2",
-}
-
-var stringToAudio = map[string]Audio{
-	"whisper-1": Whisper1,
-	"whisper-2": Whisper2,
-}
+})
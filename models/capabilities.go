@@ -0,0 +1,418 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Capabilities describes the limits and supported features of a model. Zero values indicate that a limit is not
+// applicable (e.g. MaxOutputTokens for embedding models) or that a feature is not supported. The values reflect
+// OpenAI's documentation at the time of writing; OpenAI may change them at any time.
+type Capabilities struct {
+	// ContextWindow is the maximum number of tokens in a request's input and output combined.
+	ContextWindow int
+	// MaxOutputTokens is the maximum number of tokens the model can generate in a single response.
+	MaxOutputTokens int
+
+	// Functions is whether the model supports the (deprecated) Functions and FunctionCall request fields.
+	Functions bool
+	// Tools is whether the model supports the Tools and ToolChoice request fields.
+	Tools bool
+	// ParallelToolCalls is whether the model can generate multiple tool calls in a single response.
+	ParallelToolCalls bool
+	// Vision is whether the model accepts image inputs.
+	Vision bool
+	// JSONMode is whether the model supports the "json_object" response format.
+	JSONMode bool
+	// StructuredOutputs is whether the model supports the "json_schema" response format and strict functions.
+	StructuredOutputs bool
+	// Streaming is whether responses can be streamed.
+	Streaming bool
+	// SystemRole is whether the model accepts system messages.
+	SystemRole bool
+	// Reasoning is whether the model is a reasoning model (e.g. o1), which does not accept sampling parameters such as
+	// Temperature, TopP, PresencePenalty and FrequencyPenalty, nor MaxTokens.
+	Reasoning bool
+
+	// KnowledgeCutoff is the approximate date of the most recent training data.
+	KnowledgeCutoff time.Time
+	// DeprecationDate is the date the model's deprecation was announced. Zero if the model is not deprecated.
+	DeprecationDate time.Time
+	// ShutdownDate is the date after which the model is no longer available. Zero if no shutdown is scheduled.
+	ShutdownDate time.Time
+	// Successor is the ID of the model OpenAI recommends in place of a deprecated model.
+	Successor string
+}
+
+// Deprecated reports whether the model has been deprecated.
+func (c Capabilities) Deprecated() bool {
+	return !c.DeprecationDate.IsZero()
+}
+
+// ShutDown reports whether the model's shutdown date is at or before |t|.
+func (c Capabilities) ShutDown(t time.Time) bool {
+	return !c.ShutdownDate.IsZero() && !c.ShutdownDate.After(t)
+}
+
+// Lookup returns the Capabilities of the model whose ID is |id|, e.g. "gpt-4o", of any model type.
+func Lookup(id string) (Capabilities, bool) {
+	if m, ok := chatCompletions.lookup(id); ok {
+		return m.Capabilities()
+	}
+	if m, ok := completions.lookup(id); ok {
+		return m.Capabilities()
+	}
+	if m, ok := embeddings.lookup(id); ok {
+		return m.Capabilities()
+	}
+	if m, ok := edits.lookup(id); ok {
+		return m.Capabilities()
+	}
+	if m, ok := audioModels.lookup(id); ok {
+		return m.Capabilities()
+	}
+	if m, ok := moderations.lookup(id); ok {
+		return m.Capabilities()
+	}
+	if m, ok := fineTunes.lookup(id); ok {
+		return m.Capabilities()
+	}
+
+	return Capabilities{}, false
+}
+
+// IDs returns the IDs of the predefined constants of all model types, in sorted order and without duplicates.
+func IDs() []string {
+	var ids []string
+	ids = append(ids, chatCompletions.predefined()...)
+	ids = append(ids, completions.predefined()...)
+	ids = append(ids, embeddings.predefined()...)
+	ids = append(ids, edits.predefined()...)
+	ids = append(ids, audioModels.predefined()...)
+	ids = append(ids, moderations.predefined()...)
+	ids = append(ids, fineTunes.predefined()...)
+	sort.Strings(ids)
+
+	// The same ID may be shared by several model types, e.g. base models which can also be fine-tuned.
+	var n int
+	for i, id := range ids {
+		if i == 0 || id != ids[n-1] {
+			ids[n] = id
+			n++
+		}
+	}
+
+	return ids[:n]
+}
+
+// Capabilities returns the Capabilities of the model. Fine-tuned models have the Capabilities of their base model
+// (see Base). It returns false for unknown models.
+func (c ChatCompletion) Capabilities() (Capabilities, bool) {
+	var caps, ok = chatCompletionCapabilities[c.Base()]
+	return caps, ok
+}
+
+// Base returns the model from which a fine-tuned model, whose ID has the form "ft:{base}:{org}:{suffix}:{id}", was
+// trained. For all other models, it returns c. If the base model's ID is not recognized, it returns
+// UnknownChatCompletion.
+func (c ChatCompletion) Base() ChatCompletion {
+	var id = c.String()
+	if !strings.HasPrefix(id, "ft:") {
+		return c
+	}
+
+	var base, _, _ = strings.Cut(strings.TrimPrefix(id, "ft:"), ":")
+	if m, ok := chatCompletions.lookup(base); ok {
+		return m
+	}
+
+	return UnknownChatCompletion
+}
+
+// Capabilities returns the Capabilities of the model. It returns false for unknown models.
+func (c Completion) Capabilities() (Capabilities, bool) {
+	var caps, ok = completionCapabilities[c]
+	return caps, ok
+}
+
+// Capabilities returns the Capabilities of the model. It returns false for unknown models.
+func (e Embedding) Capabilities() (Capabilities, bool) {
+	var caps, ok = embeddingCapabilities[e]
+	return caps, ok
+}
+
+// Capabilities returns the Capabilities of the model. It returns false for unknown models.
+func (e Edit) Capabilities() (Capabilities, bool) {
+	var caps, ok = editCapabilities[e]
+	return caps, ok
+}
+
+// Capabilities returns the Capabilities of the model. It returns false for unknown models.
+func (a Audio) Capabilities() (Capabilities, bool) {
+	var caps, ok = audioCapabilities[a]
+	return caps, ok
+}
+
+// Capabilities returns the Capabilities of the model. It returns false for unknown models.
+func (m Moderation) Capabilities() (Capabilities, bool) {
+	var caps, ok = moderationCapabilities[m]
+	return caps, ok
+}
+
+// Capabilities returns the Capabilities of the base model. It returns false for unknown models.
+func (f FineTune) Capabilities() (Capabilities, bool) {
+	var caps, ok = fineTuneCapabilities[f]
+	return caps, ok
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var (
+	gpt35Turbo = Capabilities{
+		ContextWindow:     16385,
+		MaxOutputTokens:   4096,
+		Functions:         true,
+		Tools:             true,
+		ParallelToolCalls: true,
+		JSONMode:          true,
+		Streaming:         true,
+		SystemRole:        true,
+		KnowledgeCutoff:   date(2021, time.September, 1),
+	}
+	gpt35Turbo0613 = Capabilities{
+		ContextWindow:   4096,
+		MaxOutputTokens: 4096,
+		Functions:       true,
+		Tools:           true,
+		Streaming:       true,
+		SystemRole:      true,
+		KnowledgeCutoff: date(2021, time.September, 1),
+		DeprecationDate: date(2023, time.November, 6),
+		ShutdownDate:    date(2024, time.September, 13),
+		Successor:       "gpt-3.5-turbo",
+	}
+	gpt4 = Capabilities{
+		ContextWindow:   8192,
+		MaxOutputTokens: 8192,
+		Functions:       true,
+		Tools:           true,
+		Streaming:       true,
+		SystemRole:      true,
+		KnowledgeCutoff: date(2021, time.September, 1),
+	}
+	gpt4With32k = Capabilities{
+		ContextWindow:   32768,
+		MaxOutputTokens: 32768,
+		Functions:       true,
+		Tools:           true,
+		Streaming:       true,
+		SystemRole:      true,
+		KnowledgeCutoff: date(2021, time.September, 1),
+		DeprecationDate: date(2024, time.June, 6),
+		ShutdownDate:    date(2025, time.June, 6),
+		Successor:       "gpt-4o",
+	}
+	gpt4Turbo = Capabilities{
+		ContextWindow:     128000,
+		MaxOutputTokens:   4096,
+		Functions:         true,
+		Tools:             true,
+		ParallelToolCalls: true,
+		JSONMode:          true,
+		Streaming:         true,
+		SystemRole:        true,
+		KnowledgeCutoff:   date(2023, time.December, 1),
+	}
+	gpt4o = Capabilities{
+		ContextWindow:     128000,
+		MaxOutputTokens:   16384,
+		Functions:         true,
+		Tools:             true,
+		ParallelToolCalls: true,
+		Vision:            true,
+		JSONMode:          true,
+		StructuredOutputs: true,
+		Streaming:         true,
+		SystemRole:        true,
+		KnowledgeCutoff:   date(2023, time.October, 1),
+	}
+	o1 = Capabilities{
+		ContextWindow:   128000,
+		MaxOutputTokens: 32768,
+		Reasoning:       true,
+		KnowledgeCutoff: date(2023, time.October, 1),
+	}
+)
+
+var chatCompletionCapabilities = map[ChatCompletion]Capabilities{
+	GPT3Dot5Turbo: gpt35Turbo,
+	GPT3Dot5Turbo0301: {
+		ContextWindow:   4096,
+		MaxOutputTokens: 4096,
+		Streaming:       true,
+		SystemRole:      true,
+		KnowledgeCutoff: date(2021, time.September, 1),
+		DeprecationDate: date(2023, time.June, 13),
+		ShutdownDate:    date(2024, time.June, 13),
+		Successor:       "gpt-3.5-turbo",
+	},
+	GPT3Dot5Turbo0613: gpt35Turbo0613,
+	GPT3Dot5Turbo16K: with(gpt35Turbo0613, func(c *Capabilities) {
+		c.ContextWindow = 16385
+	}),
+	GPT3Dot5Turbo1106: gpt35Turbo,
+	GPT3Dot5Turbo0125: gpt35Turbo,
+	GPT4:              gpt4,
+	GPT4_0314: with(gpt4, func(c *Capabilities) {
+		c.Functions, c.Tools = false, false
+		c.DeprecationDate = date(2023, time.June, 13)
+		c.ShutdownDate = date(2024, time.June, 13)
+		c.Successor = "gpt-4"
+	}),
+	GPT4_0613: gpt4,
+	GPT4_32K:  gpt4With32k,
+	GPT4_32K_0314: with(gpt4With32k, func(c *Capabilities) {
+		c.Functions, c.Tools = false, false
+	}),
+	GPT4_32K_0613: gpt4With32k,
+	GPT4Turbo1106Preview: with(gpt4Turbo, func(c *Capabilities) {
+		c.KnowledgeCutoff = date(2023, time.April, 1)
+	}),
+	GPT4Turbo0125Preview: gpt4Turbo,
+	GPT4TurboPreview:     gpt4Turbo,
+	GPT4o:                gpt4o,
+	GPT4o20240503: with(gpt4o, func(c *Capabilities) {
+		c.MaxOutputTokens = 4096
+		c.StructuredOutputs = false
+	}),
+	GPT4oMini: gpt4o,
+	O1Preview: o1,
+	O1Mini: with(o1, func(c *Capabilities) {
+		c.MaxOutputTokens = 65536
+	}),
+}
+
+var (
+	// gpt3 describes the original GPT-3 completion models, shut down along with the Edits endpoint.
+	gpt3 = Capabilities{
+		ContextWindow:   2049,
+		MaxOutputTokens: 2049,
+		Streaming:       true,
+		KnowledgeCutoff: date(2019, time.October, 1),
+		DeprecationDate: date(2023, time.July, 6),
+		ShutdownDate:    date(2024, time.January, 4),
+		Successor:       "gpt-3.5-turbo-instruct",
+	}
+	gpt35 = with(gpt3, func(c *Capabilities) {
+		c.ContextWindow, c.MaxOutputTokens = 4097, 4097
+		c.KnowledgeCutoff = date(2021, time.June, 1)
+	})
+	codex = Capabilities{
+		ContextWindow:   8001,
+		MaxOutputTokens: 8001,
+		Streaming:       true,
+		KnowledgeCutoff: date(2021, time.June, 1),
+		DeprecationDate: date(2023, time.March, 20),
+		ShutdownDate:    date(2023, time.March, 23),
+		Successor:       "gpt-3.5-turbo",
+	}
+)
+
+var completionCapabilities = map[Completion]Capabilities{
+	TextDavinci003: gpt35,
+	TextDavinci002: gpt35,
+	TextCurie001: with(gpt3, func(c *Capabilities) {
+		c.Successor = "davinci-002"
+	}),
+	TextBabbage001: with(gpt3, func(c *Capabilities) {
+		c.Successor = "babbage-002"
+	}),
+	TextAda001: with(gpt3, func(c *Capabilities) {
+		c.Successor = "babbage-002"
+	}),
+	TextDavinci001:      gpt3,
+	DavinciInstructBeta: gpt3,
+	CurieInstructBeta:   gpt3,
+	CodeDavinci002:      codex,
+	CodeCushman001: with(codex, func(c *Capabilities) {
+		c.ContextWindow, c.MaxOutputTokens = 2048, 2048
+	}),
+	CodeDavinci001:       codex,
+	TextDavinciInsert002: gpt35,
+	TextDavinciInsert001: gpt35,
+}
+
+var embedding001 = Capabilities{
+	ContextWindow:   2046,
+	DeprecationDate: date(2023, time.July, 6),
+	ShutdownDate:    date(2024, time.January, 4),
+	Successor:       "text-embedding-ada-002",
+}
+
+var embeddingCapabilities = map[Embedding]Capabilities{
+	AdaEmbeddingV2: {
+		ContextWindow:   8191,
+		KnowledgeCutoff: date(2021, time.September, 1),
+	},
+	AdaSimilarity:         embedding001,
+	BabbageSimilarity:     embedding001,
+	CurieSimilarity:       embedding001,
+	DavinciSimilarity:     embedding001,
+	AdaSearchDocument:     embedding001,
+	AdaSearchQuery:        embedding001,
+	BabbageSearchDocument: embedding001,
+	BabbageSearchQuery:    embedding001,
+	CurieSearchDocument:   embedding001,
+	CurieSearchQuery:      embedding001,
+	DavinciSearchDocument: embedding001,
+	DavinciSearchQuery:    embedding001,
+	AdaCodeSearchCode:     embedding001,
+	AdaCodeSearchText:     embedding001,
+	BabbageCodeSearchCode: embedding001,
+	BabbageCodeSearchText: embedding001,
+}
+
+var edit001 = Capabilities{
+	DeprecationDate: date(2023, time.July, 6),
+	ShutdownDate:    date(2024, time.January, 4),
+	Successor:       "gpt-4",
+}
+
+var editCapabilities = map[Edit]Capabilities{
+	TextDavinciEdit001: edit001,
+	CodeDavinciEdit001: edit001,
+}
+
+var audioCapabilities = map[Audio]Capabilities{
+	Whisper1: {},
+	Whisper2: {},
+}
+
+var moderationCapabilities = map[Moderation]Capabilities{
+	TextModerationStable: {ContextWindow: 32768},
+	TextModerationLatest: {ContextWindow: 32768},
+}
+
+var fineTuneCapabilities = map[FineTune]Capabilities{
+	Davinci: with(gpt3, func(c *Capabilities) {
+		c.Successor = "davinci-002"
+	}),
+	Curie: with(gpt3, func(c *Capabilities) {
+		c.Successor = "davinci-002"
+	}),
+	Babbage: with(gpt3, func(c *Capabilities) {
+		c.Successor = "babbage-002"
+	}),
+	Ada: with(gpt3, func(c *Capabilities) {
+		c.Successor = "babbage-002"
+	}),
+}
+
+// with returns a copy of |c| modified by |f|.
+func with(c Capabilities, f func(*Capabilities)) Capabilities {
+	f(&c)
+	return c
+}
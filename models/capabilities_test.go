@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapabilitiesComplete(t *testing.T) {
+	// Every enum value up to the first without a string representation must have Capabilities.
+	for m := ChatCompletion(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+	for m := Completion(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+	for m := Embedding(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+	for m := Edit(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+	for m := Audio(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+	for m := Moderation(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+	for m := FineTune(1); m.String() != ""; m++ {
+		if _, ok := m.Capabilities(); !ok {
+			t.Errorf("no capabilities for %s", m)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var c, ok = Lookup("gpt-4o-mini")
+	if !ok || !c.Vision || c.ContextWindow != 128000 {
+		t.Errorf("unexpected capabilities for gpt-4o-mini: %+v", c)
+	}
+
+	if c, ok = Lookup("text-davinci-003"); !ok || !c.Deprecated() || !c.ShutDown(date(2024, time.January, 4)) {
+		t.Errorf("expected text-davinci-003 to be shut down: %+v", c)
+	}
+	if c.ShutDown(date(2024, time.January, 3)) {
+		t.Error("expected text-davinci-003 to be available before its shutdown date")
+	}
+
+	if _, ok = Lookup("nope"); ok {
+		t.Error("expected unknown model to not be found")
+	}
+}
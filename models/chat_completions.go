@@ -96,7 +96,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (c ChatCompletion) String() string {
-	return chatCompletionToString[c]
+	return chatCompletions.id(c)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -105,19 +105,23 @@ func (c ChatCompletion) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewChatCompletion.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (c *ChatCompletion) UnmarshalText(b []byte) error {
-	if val, ok := stringToChatCompletion[(string(b))]; ok {
-		*c = val
-		return nil
-	}
+	var err error
+	*c, err = chatCompletions.decode(string(b))
 
-	*c = UnknownChatCompletion
+	return err
+}
 
-	return nil
+// NewChatCompletion returns the ChatCompletion whose ID is |id|. If |id| is not one of the predefined constants (e.g.
+// it is the ID of a fine-tuned model, an Azure deployment or a newly released model), a new value is returned which
+// marshals back to |id|. See the package documentation for the limits of such values.
+func NewChatCompletion(id string) ChatCompletion {
+	return chatCompletions.intern(id)
 }
 
-var chatCompletionToString = map[ChatCompletion]string{
+var chatCompletions = newRegistry(map[ChatCompletion]string{
 	GPT3Dot5Turbo:        "gpt-3.5-turbo",
 	GPT3Dot5Turbo0301:    "gpt-3.5-turbo-0301",
 	GPT3Dot5Turbo0613:    "gpt-3.5-turbo-0613",
@@ -138,27 +142,4 @@ var chatCompletionToString = map[ChatCompletion]string{
 	GPT4oMini:            "gpt-4o-mini",
 	O1Preview:            "o1-preview",
 	O1Mini:               "o1-mini",
-}
-
-var stringToChatCompletion = map[string]ChatCompletion{
-	"gpt-3.5-turbo":       GPT3Dot5Turbo,
-	"gpt-3.5-turbo-0301":  GPT3Dot5Turbo0301,
-	"gpt-3.5-turbo-0613":  GPT3Dot5Turbo0613,
-	"gpt-3.5-turbo-16k":   GPT3Dot5Turbo16K,
-	"gpt-3.5-turbo-1106":  GPT3Dot5Turbo1106,
-	"gpt-3.5-turbo-0125":  GPT3Dot5Turbo0125,
-	"gpt-4":               GPT4,
-	"gpt-4-0314":          GPT4_0314,
-	"gpt-4-0613":          GPT4_0613,
-	"gpt-4-32k":           GPT4_32K,
-	"gpt-4-32k-0314":      GPT4_32K_0314,
-	"gpt-4-32k-0613":      GPT4_32K_0613,
-	"gpt-4-1106-preview":  GPT4Turbo1106Preview,
-	"gpt-4-0125-preview":  GPT4Turbo0125Preview,
-	"gpt-4-turbo-preview": GPT4TurboPreview,
-	"gpt-4o":              GPT4o,
-	"gpt-4o-2024-05-13":   GPT4o20240503,
-	"gpt-4o-mini":         GPT4oMini,
-	"o1-preview":          O1Preview,
-	"o1-mini":             O1Mini,
-}
+})
@@ -1,5 +1,11 @@
 // Package models contains the enum values which represent the various
 // models used by all OpenAI endpoints.
+//
+// IDs which are not predefined constants (e.g. those of fine-tuned models) are assigned values as they are first
+// decoded or passed to a New function such as NewChatCompletion, up to 4096 per type; once that limit is reached,
+// decoding further unrecognized IDs fails with ErrTooManyModels, and New functions return the type's unknown value,
+// which no longer round-trips. These values depend on the order in which IDs are first seen, so they differ between
+// processes: compare and store such models by their String, never by their numeric value.
 package models
 
 // Completion represents all models available for use with the Completions endpoint.
@@ -79,7 +85,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (c Completion) String() string {
-	return completionToString[c]
+	return completions.id(c)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -88,19 +94,23 @@ func (c Completion) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewCompletion.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (c *Completion) UnmarshalText(b []byte) error {
-	if val, ok := stringToCompletion[(string(b))]; ok {
-		*c = val
-		return nil
-	}
+	var err error
+	*c, err = completions.decode(string(b))
 
-	*c = UnknownCompletion
+	return err
+}
 
-	return nil
+// NewCompletion returns the Completion whose ID is |id|. If |id| is not one of the predefined constants (e.g. it is the
+// ID of a fine-tuned model, an Azure deployment or a newly released model), a new value is returned which marshals back
+// to |id|. See the package documentation for the limits of such values.
+func NewCompletion(id string) Completion {
+	return completions.intern(id)
 }
 
-var completionToString = map[Completion]string{
+var completions = newRegistry(map[Completion]string{
 	TextDavinci003:       "text-davinci-003",
 	TextDavinci002:       "text-davinci-002",
 	TextCurie001:         "text-curie-001",
@@ -114,20 +124,4 @@ var completionToString = map[Completion]string{
 	CodeDavinci001:       "code-davinci-001",
 	TextDavinciInsert002: "text-davinci-insert-002",
 	TextDavinciInsert001: "text-davinci-insert-001",
-}
-
-var stringToCompletion = map[string]Completion{
-	"text-davinci-003":        TextDavinci003,
-	"text-davinci-002":        TextDavinci002,
-	"text-curie-001":          TextCurie001,
-	"text-babbage-001":        TextBabbage001,
-	"text-ada-001":            TextAda001,
-	"text-davinci-001":        TextDavinci001,
-	"davinci-instruct-beta":   DavinciInstructBeta,
-	"curie-instruct-beta":     CurieInstructBeta,
-	"code-davinci-002":        CodeDavinci002,
-	"code-cushman-001":        CodeCushman001,
-	"code-davinci-001":        CodeDavinci001,
-	"text-davinci-insert-002": TextDavinciInsert002,
-	"text-davinci-insert-001": TextDavinciInsert001,
-}
+})
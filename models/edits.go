@@ -14,7 +14,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (e Edit) String() string {
-	return editToString[e]
+	return edits.id(e)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -23,26 +23,25 @@ func (e Edit) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewEdit.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (e *Edit) UnmarshalText(b []byte) error {
-	if val, ok := stringToEdit[(string(b))]; ok {
-		*e = val
-		return nil
-	}
+	var err error
+	*e, err = edits.decode(string(b))
 
-	*e = UnknownEdit
+	return err
+}
 
-	return nil
+// NewEdit returns the Edit whose ID is |id|. If |id| is not one of the predefined constants (e.g. it is the ID of a
+// fine-tuned model, an Azure deployment or a newly released model), a new value is returned which marshals back to
+// |id|. See the package documentation for the limits of such values.
+func NewEdit(id string) Edit {
+	return edits.intern(id)
 }
 
-var editToString = map[Edit]string{
+var edits = newRegistry(map[Edit]string{
 	// TextDavinciEdit001 can be used to edit text, rather than just completing it.
 	TextDavinciEdit001: "text-davinci-edit-001",
 	// CodeDavinciEdit001 can be used to edit code, rather than just completing it.
 	CodeDavinciEdit001: "code-davinci-edit-001",
-}
-
-var stringToEdit = map[string]Edit{
-	"text-davinci-edit-001": TextDavinciEdit001,
-	"code-davinci-edit-001": CodeDavinciEdit001,
-}
+})
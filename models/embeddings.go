@@ -56,7 +56,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (e Embedding) String() string {
-	return enumToString[e]
+	return embeddings.id(e)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -65,19 +65,23 @@ func (e Embedding) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewEmbedding.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (e *Embedding) UnmarshalText(b []byte) error {
-	if val, ok := stringToEnum[(string(b))]; ok {
-		*e = val
-		return nil
-	}
+	var err error
+	*e, err = embeddings.decode(string(b))
 
-	*e = Unknown
+	return err
+}
 
-	return nil
+// NewEmbedding returns the Embedding whose ID is |id|. If |id| is not one of the predefined constants (e.g. it is the
+// ID of a fine-tuned model, an Azure deployment or a newly released model), a new value is returned which marshals back
+// to |id|. See the package documentation for the limits of such values.
+func NewEmbedding(id string) Embedding {
+	return embeddings.intern(id)
 }
 
-var enumToString = map[Embedding]string{
+var embeddings = newRegistry(map[Embedding]string{
 	AdaSimilarity:         "text-similarity-ada-001",
 	BabbageSimilarity:     "text-similarity-babbage-001",
 	CurieSimilarity:       "text-similarity-curie-001",
@@ -95,24 +99,4 @@ var enumToString = map[Embedding]string{
 	BabbageCodeSearchCode: "code-search-babbage-code-001",
 	BabbageCodeSearchText: "code-search-babbage-text-001",
 	AdaEmbeddingV2:        "text-embedding-ada-002",
-}
-
-var stringToEnum = map[string]Embedding{
-	"text-similarity-ada-001":       AdaSimilarity,
-	"text-similarity-babbage-001":   BabbageSimilarity,
-	"text-similarity-curie-001":     CurieSimilarity,
-	"text-similarity-davinci-001":   DavinciSimilarity,
-	"text-search-ada-doc-001":       AdaSearchDocument,
-	"text-search-ada-query-001":     AdaSearchQuery,
-	"text-search-babbage-doc-001":   BabbageSearchDocument,
-	"text-search-babbage-query-001": BabbageSearchQuery,
-	"text-search-curie-doc-001":     CurieSearchDocument,
-	"text-search-curie-query-001":   CurieSearchQuery,
-	"text-search-davinci-doc-001":   DavinciSearchDocument,
-	"text-search-davinci-query-001": DavinciSearchQuery,
-	"code-search-ada-code-001":      AdaCodeSearchCode,
-	"code-search-ada-text-001":      AdaCodeSearchText,
-	"code-search-babbage-code-001":  BabbageCodeSearchCode,
-	"code-search-babbage-text-001":  BabbageCodeSearchText,
-	"text-embedding-ada-002":        AdaEmbeddingV2,
-}
+})
@@ -23,7 +23,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (f FineTune) String() string {
-	return fineTuneToString[f]
+	return fineTunes.id(f)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -32,31 +32,28 @@ func (f FineTune) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewFineTune.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (f *FineTune) UnmarshalText(b []byte) error {
-	if val, ok := stringToFineTune[(string(b))]; ok {
-		*f = val
-		return nil
-	}
+	var err error
+	*f, err = fineTunes.decode(string(b))
 
-	*f = UnknownFineTune
+	return err
+}
 
-	return nil
+// NewFineTune returns the FineTune whose ID is |id|. If |id| is not one of the predefined constants (e.g. it is the ID
+// of a fine-tuned model, an Azure deployment or a newly released model), a new value is returned which marshals back to
+// |id|. See the package documentation for the limits of such values.
+func NewFineTune(id string) FineTune {
+	return fineTunes.intern(id)
 }
 
-var fineTuneToString = map[FineTune]string{
+var fineTunes = newRegistry(map[FineTune]string{
 	Davinci: "davinci",
 	Curie:   "curie",
 	Ada:     "ada",
 	Babbage: "babbage",
-}
-
-var stringToFineTune = map[string]FineTune{
-	"davinci": Davinci,
-	"curie":   Curie,
-	"ada":     Ada,
-	"babbage": Babbage,
-}
+})
 
 // FineTunedModel represents the name of a fine-tuned model which was
 // previously generated.
@@ -14,7 +14,7 @@ const (
 
 // String implements the fmt.Stringer interface.
 func (m Moderation) String() string {
-	return moderationToString[m]
+	return moderations.id(m)
 }
 
 // MarshalText implements the encoding.TextMarshaler interface.
@@ -23,26 +23,25 @@ func (m Moderation) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
-// On unrecognized value, it sets |e| to Unknown.
+// Unrecognized IDs are preserved; see NewModeration.
+// Returns ErrTooManyModels if too many unrecognized IDs have been decoded already.
 func (m *Moderation) UnmarshalText(b []byte) error {
-	if val, ok := stringToModeration[(string(b))]; ok {
-		*m = val
-		return nil
-	}
+	var err error
+	*m, err = moderations.decode(string(b))
 
-	*m = UnknownModeration
+	return err
+}
 
-	return nil
+// NewModeration returns the Moderation whose ID is |id|. If |id| is not one of the predefined constants (e.g. it is the
+// ID of a fine-tuned model, an Azure deployment or a newly released model), a new value is returned which marshals back
+// to |id|. See the package documentation for the limits of such values.
+func NewModeration(id string) Moderation {
+	return moderations.intern(id)
 }
 
-var moderationToString = map[Moderation]string{
+var moderations = newRegistry(map[Moderation]string{
 	// TextDavinciEdit001 can be used to edit text, rather than just completing it.
 	TextModerationStable: "text-moderation-stable",
 	// CodeDavinciEdit001 can be used to edit code, rather than just completing it.
 	TextModerationLatest: "text-moderation-latest",
-}
-
-var stringToModeration = map[string]Moderation{
-	"text-moderation-stable": TextModerationStable,
-	"text-moderation-latest": TextModerationLatest,
-}
+})
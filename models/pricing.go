@@ -0,0 +1,106 @@
+package models
+
+import (
+	"sync"
+
+	"github.com/fabiustech/openai/images"
+)
+
+// Pricing is the price of using a model, in US dollars. Prices change; the predefined table reflects OpenAI's list
+// prices as of October 2024 and can be overridden with SetPricing.
+type Pricing struct {
+	// Input is the price per million input (prompt) tokens. For embedding models, it is the price per million
+	// embedded tokens.
+	Input float64
+	// CachedInput is the price per million input tokens read from the prompt cache. If zero, cached tokens are billed
+	// at the Input price.
+	CachedInput float64
+	// Output is the price per million output (completion) tokens, including reasoning tokens.
+	Output float64
+	// Images is the price per generated image, by size.
+	Images map[images.Size]float64
+	// AudioMinute is the price per minute of input audio.
+	AudioMinute float64
+}
+
+// DALLE2 is the ID of the model used by the images endpoints, for use with PriceOf.
+const DALLE2 = "dall-e-2"
+
+// PriceOf returns the Pricing of the model whose ID is |id|, e.g. "gpt-4o". Fine-tuned models are billed at different
+// prices to their base model, so they have no Pricing unless it is set with SetPricing. The returned Pricing is a copy,
+// which may be modified freely.
+func PriceOf(id string) (Pricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+
+	var p, ok = pricing[id]
+
+	return p.clone(), ok
+}
+
+// SetPricing sets the Pricing of the model whose ID is |id|, overriding the predefined table. It is safe to call
+// concurrently with PriceOf.
+func SetPricing(id string, p Pricing) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+
+	pricing[id] = p.clone()
+}
+
+// clone returns a deep copy of |p|, so that the table's Images maps are never shared with callers.
+func (p Pricing) clone() Pricing {
+	if p.Images != nil {
+		var imgs = make(map[images.Size]float64, len(p.Images))
+		for k, v := range p.Images {
+			imgs[k] = v
+		}
+		p.Images = imgs
+	}
+
+	return p
+}
+
+var pricingMu sync.RWMutex
+
+var pricing = map[string]Pricing{
+	"gpt-3.5-turbo":       {Input: 0.5, Output: 1.5},
+	"gpt-3.5-turbo-0125":  {Input: 0.5, Output: 1.5},
+	"gpt-3.5-turbo-1106":  {Input: 1, Output: 2},
+	"gpt-3.5-turbo-0613":  {Input: 1.5, Output: 2},
+	"gpt-3.5-turbo-0301":  {Input: 1.5, Output: 2},
+	"gpt-3.5-turbo-16k":   {Input: 3, Output: 4},
+	"gpt-4":               {Input: 30, Output: 60},
+	"gpt-4-0613":          {Input: 30, Output: 60},
+	"gpt-4-0314":          {Input: 30, Output: 60},
+	"gpt-4-32k":           {Input: 60, Output: 120},
+	"gpt-4-32k-0314":      {Input: 60, Output: 120},
+	"gpt-4-32k-0613":      {Input: 60, Output: 120},
+	"gpt-4-1106-preview":  {Input: 10, Output: 30},
+	"gpt-4-0125-preview":  {Input: 10, Output: 30},
+	"gpt-4-turbo-preview": {Input: 10, Output: 30},
+	"gpt-4o":              {Input: 2.5, CachedInput: 1.25, Output: 10},
+	"gpt-4o-2024-05-13":   {Input: 5, Output: 15},
+	"gpt-4o-mini":         {Input: 0.15, CachedInput: 0.075, Output: 0.6},
+	"o1-preview":          {Input: 15, CachedInput: 7.5, Output: 60},
+	"o1-mini":             {Input: 3, CachedInput: 1.5, Output: 12},
+
+	"text-davinci-003": {Input: 20, Output: 20},
+	"text-davinci-002": {Input: 20, Output: 20},
+	"text-davinci-001": {Input: 20, Output: 20},
+	"text-curie-001":   {Input: 2, Output: 2},
+	"text-babbage-001": {Input: 0.5, Output: 0.5},
+	"text-ada-001":     {Input: 0.4, Output: 0.4},
+
+	"text-embedding-ada-002": {Input: 0.1},
+
+	"text-moderation-stable": {},
+	"text-moderation-latest": {},
+
+	"whisper-1": {AudioMinute: 0.006},
+
+	DALLE2: {Images: map[images.Size]float64{
+		images.Size256x256:   0.016,
+		images.Size512x512:   0.018,
+		images.Size1024x1024: 0.02,
+	}},
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/fabiustech/openai/images"
+)
+
+func TestPriceOfReturnsCopy(t *testing.T) {
+	var p, ok = PriceOf(DALLE2)
+	if !ok || len(p.Images) == 0 {
+		t.Fatalf("expected %s to have image pricing", DALLE2)
+	}
+	var want = p.Images[images.Size256x256]
+	p.Images[images.Size256x256] = 1000
+
+	if p, _ = PriceOf(DALLE2); p.Images[images.Size256x256] != want {
+		t.Errorf("expected modifying the returned Pricing not to change the table, got %v", p.Images[images.Size256x256])
+	}
+}
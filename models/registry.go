@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// dynamicBase is the first value assigned to model IDs which are not predefined constants. Starting well above the
+// constants ensures that iterating over the predefined values stops at the first gap.
+const dynamicBase = 1 << 16
+
+// maxDynamic is the maximum number of values assigned to unrecognized IDs per registry, so that decoding arbitrary IDs
+// (e.g. in a proxy) cannot grow the registry without bound.
+const maxDynamic = 4096
+
+// ErrTooManyModels is returned when decoding an unrecognized model ID once the maximum number of unrecognized IDs of
+// its type has been reached. See the package documentation.
+var ErrTooManyModels = errors.New("models: too many unrecognized model IDs")
+
+// registry maps the values of a model enum to and from their IDs. In addition to the predefined constants, it assigns
+// a new value to each unrecognized ID on first use, so that the IDs of fine-tuned models, Azure deployments, models
+// served by OpenAI-compatible servers and models released after this package round-trip unchanged.
+type registry[T ~int] struct {
+	mu   sync.RWMutex
+	ids  map[T]string
+	vals map[string]T
+	next T
+}
+
+func newRegistry[T ~int](ids map[T]string) *registry[T] {
+	var r = &registry[T]{
+		ids:  ids,
+		vals: make(map[string]T, len(ids)),
+		next: dynamicBase,
+	}
+	for v, id := range ids {
+		r.vals[id] = v
+	}
+
+	return r
+}
+
+// id returns the ID of |v|, or "" if |v| is unknown.
+func (r *registry[T]) id(v T) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.ids[v]
+}
+
+// lookup returns the value of |id|, if it is predefined or has been interned.
+func (r *registry[T]) lookup(id string) (T, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var v, ok = r.vals[id]
+
+	return v, ok
+}
+
+// intern returns the value of |id|, assigning a new value if |id| is unrecognized. The empty ID, and unrecognized IDs
+// once maxDynamic values have been assigned, map to the zero (unknown) value.
+func (r *registry[T]) intern(id string) T {
+	if id == "" {
+		return 0
+	}
+	if v, ok := r.lookup(id); ok {
+		return v
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.vals[id]; ok {
+		return v
+	}
+
+	if r.next >= dynamicBase+maxDynamic {
+		return 0
+	}
+
+	var v = r.next
+	r.next++
+	r.ids[v] = id
+	r.vals[id] = v
+
+	return v
+}
+
+// decode returns the value of |id| as intern does, but returns an error, rather than the zero value, for an
+// unrecognized ID once maxDynamic values have been assigned.
+func (r *registry[T]) decode(id string) (T, error) {
+	var v = r.intern(id)
+	if v == 0 && id != "" {
+		return 0, fmt.Errorf("%w: cannot decode %q", ErrTooManyModels, id)
+	}
+
+	return v, nil
+}
+
+// predefined returns the IDs of the predefined constants.
+func (r *registry[T]) predefined() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids = make([]string, 0, len(r.ids))
+	for v, id := range r.ids {
+		if v < dynamicBase {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestUnmarshalPreservesUnknownIDs(t *testing.T) {
+	var tcs = []string{
+		`{"model":"gpt-4o"}`,
+		`{"model":"ft:gpt-4o-mini-2024-07-18:my-org::abc123"}`,
+		`{"model":"my-azure-deployment"}`,
+	}
+
+	for _, in := range tcs {
+		var v struct {
+			Model ChatCompletion `json:"model"`
+		}
+		if err := json.Unmarshal([]byte(in), &v); err != nil {
+			t.Fatal(err)
+		}
+
+		var out, err = json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != in {
+			t.Errorf("expected %s, got %s", in, out)
+		}
+	}
+
+	if NewChatCompletion("gpt-4o") != GPT4o {
+		t.Error("expected NewChatCompletion to return the predefined constant")
+	}
+	if a, b := NewEmbedding("text-embedding-3-small"), NewEmbedding("text-embedding-3-small"); a != b || a == Unknown {
+		t.Errorf("expected the same ID to return the same value, got %d and %d", a, b)
+	}
+	if NewChatCompletion("") != UnknownChatCompletion {
+		t.Error("expected the empty ID to be unknown")
+	}
+}
+
+func TestBase(t *testing.T) {
+	var ft = NewChatCompletion("ft:gpt-4o-mini:my-org:custom:abc123")
+	if ft.Base() != GPT4oMini {
+		t.Errorf("expected base %s, got %s", GPT4oMini, ft.Base())
+	}
+	if _, ok := ft.Capabilities(); !ok {
+		t.Error("expected fine-tuned model to have its base model's capabilities")
+	}
+
+	if GPT4.Base() != GPT4 {
+		t.Error("expected a model which is not fine-tuned to be its own base")
+	}
+	if NewChatCompletion("ft:unknown:my-org::abc123").Base() != UnknownChatCompletion {
+		t.Error("expected an unrecognized base model to be unknown")
+	}
+}
+
+func TestRegistryIsBounded(t *testing.T) {
+	var r = newRegistry(map[Audio]string{Whisper1: "whisper-1"})
+	for i := 0; i < maxDynamic; i++ {
+		if r.intern(fmt.Sprintf("model-%d", i)) == UnknownAudioModel {
+			t.Fatalf("expected model-%d to be assigned a value", i)
+		}
+	}
+
+	if v := r.intern("one-too-many"); v != UnknownAudioModel {
+		t.Errorf("expected the unknown value once the registry is full, got %d", v)
+	}
+	if r.intern("model-0") == UnknownAudioModel || r.intern("whisper-1") != Whisper1 {
+		t.Error("expected existing IDs to be unaffected once the registry is full")
+	}
+}
+
+func TestUnmarshalFailsOnceRegistryIsFull(t *testing.T) {
+	var r = newRegistry(map[Audio]string{Whisper1: "whisper-1"})
+	for i := 0; i < maxDynamic; i++ {
+		if _, err := r.decode(fmt.Sprintf("model-%d", i)); err != nil {
+			t.Fatalf("model-%d: unexpected error: %v", i, err)
+		}
+	}
+
+	if _, err := r.decode("one-too-many"); !errors.Is(err, ErrTooManyModels) {
+		t.Errorf("expected ErrTooManyModels, got %v", err)
+	}
+	if v, err := r.decode("whisper-1"); err != nil || v != Whisper1 {
+		t.Errorf("expected predefined IDs to decode once the registry is full, got %v, %v", v, err)
+	}
+	if v, err := r.decode(""); err != nil || v != UnknownAudioModel {
+		t.Errorf("expected the empty ID to decode as unknown, got %v, %v", v, err)
+	}
+}
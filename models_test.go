@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+)
+
+func TestModels(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/models":
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o","object":"model","created":1715367049,"owned_by":"system"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/models/gpt-4o":
+			_, _ = w.Write([]byte(`{"id":"gpt-4o","object":"model","created":1715367049,"owned_by":"system"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/models/ft:gpt-4o-mini:acme::abc123":
+			_, _ = w.Write([]byte(`{"id":"ft:gpt-4o-mini:acme::abc123","object":"model","deleted":true}`))
+		default:
+			http.Error(w, "the resource path doesn't exist", http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	var client, _ = newTestClient(ts.URL)
+	var ctx = context.Background()
+
+	var l, err = client.ListModels(ctx)
+	if err != nil {
+		t.Fatalf("ListModels error: %v", err)
+	}
+	if len(l.Data) != 1 || l.Data[0].ID != "gpt-4o" || l.Data[0].OwnedBy != "system" || l.Data[0].Created != 1715367049 {
+		t.Errorf("unexpected models: %+v", l.Data)
+	}
+
+	var m *Model
+	if m, err = client.RetrieveModel(ctx, "gpt-4o"); err != nil {
+		t.Fatalf("RetrieveModel error: %v", err)
+	}
+	if m.ID != "gpt-4o" {
+		t.Errorf("expected gpt-4o, got %q", m.ID)
+	}
+
+	var d *ModelDeletionResponse
+	if d, err = client.DeleteModel(ctx, "ft:gpt-4o-mini:acme::abc123"); err != nil {
+		t.Fatalf("DeleteModel error: %v", err)
+	}
+	if !d.Deleted {
+		t.Error("expected model to be deleted")
+	}
+}
+
+func TestReconcileModels(t *testing.T) {
+	var available = []*Model{
+		{ID: "gpt-4o"},
+		{ID: "gpt-3.5-turbo-0613"},
+		{ID: "gpt-5-turbo"},
+		{ID: "ft:gpt-4o-mini:acme::abc123"},
+		{ID: "davinci:ft-acme-2023-01-01-00-00-00"},
+	}
+
+	var r = ReconcileModels(available)
+	if !equalFields(r.Unknown, []string{"gpt-5-turbo"}) {
+		t.Errorf("unexpected unknown models: %q", r.Unknown)
+	}
+	if !equalFields(r.FineTuned, []string{"davinci:ft-acme-2023-01-01-00-00-00", "ft:gpt-4o-mini:acme::abc123"}) {
+		t.Errorf("unexpected fine-tuned models: %q", r.FineTuned)
+	}
+	if len(r.Retired) != len(models.IDs())-2 {
+		t.Errorf("expected all but 2 predefined models to be retired, got %d of %d", len(r.Retired), len(models.IDs()))
+	}
+	for _, id := range r.Retired {
+		if id == "gpt-4o" {
+			t.Error("expected gpt-4o not to be retired")
+		}
+	}
+}
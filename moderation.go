@@ -2,7 +2,6 @@ package openai
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/fabiustech/openai/models"
 
@@ -56,13 +55,8 @@ type ModerationResponse struct {
 
 // CreateModeration classifies if text violates OpenAI's Content Policy.
 func (c *Client) CreateModeration(ctx context.Context, mr *ModerationRequest) (*ModerationResponse, error) {
-	var b, err = c.post(ctx, routes.Moderations, mr)
-	if err != nil {
-		return nil, err
-	}
-
 	var resp = &ModerationResponse{}
-	if err = json.Unmarshal(b, resp); err != nil {
+	if err := c.post(ctx, routes.Moderations, mr, resp); err != nil {
 		return nil, err
 	}
 
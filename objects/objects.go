@@ -30,6 +30,8 @@ const (
 	Engine
 	// ChatCompletion represents a chat completion.
 	ChatCompletion
+	// ChatCompletionChunk represents a streamed chunk of a chat completion.
+	ChatCompletionChunk
 )
 
 // String implements the fmt.Stringer interface.
@@ -56,29 +58,31 @@ func (o *Object) UnmarshalText(b []byte) error {
 }
 
 var objectToString = map[Object]string{
-	Model:          "model",
-	List:           "list",
-	TextCompletion: "text_completion",
-	CodeCompletion: "code_completion",
-	Edit:           "edit",
-	Embedding:      "embedding",
-	File:           "file",
-	FineTune:       "fine-tune",
-	FineTimeEvent:  "fine-tune-event",
-	Engine:         "engine",
-	ChatCompletion: "chat.completion",
+	Model:               "model",
+	List:                "list",
+	TextCompletion:      "text_completion",
+	CodeCompletion:      "code_completion",
+	Edit:                "edit",
+	Embedding:           "embedding",
+	File:                "file",
+	FineTune:            "fine-tune",
+	FineTimeEvent:       "fine-tune-event",
+	Engine:              "engine",
+	ChatCompletion:      "chat.completion",
+	ChatCompletionChunk: "chat.completion.chunk",
 }
 
 var stringToObject = map[string]Object{
-	"model":           Model,
-	"list":            List,
-	"text_completion": TextCompletion,
-	"code_completion": CodeCompletion,
-	"edit":            Edit,
-	"embedding":       Embedding,
-	"file":            File,
-	"fine-tune":       FineTune,
-	"fine-tune-event": FineTimeEvent,
-	"engine":          Engine,
-	"chat.completion": ChatCompletion,
+	"model":                 Model,
+	"list":                  List,
+	"text_completion":       TextCompletion,
+	"code_completion":       CodeCompletion,
+	"edit":                  Edit,
+	"embedding":             Embedding,
+	"file":                  File,
+	"fine-tune":             FineTune,
+	"fine-tune-event":       FineTimeEvent,
+	"engine":                Engine,
+	"chat.completion":       ChatCompletion,
+	"chat.completion.chunk": ChatCompletionChunk,
 }
@@ -0,0 +1,70 @@
+package openai
+
+import (
+	"net/http"
+)
+
+// Option configures a Client. Options are passed to NewClient or NewClientWithOrg and applied in order.
+type Option func(*Client)
+
+// WithHTTPClient configures the Client to send all requests using |hc| rather than http.DefaultClient. This allows
+// setting timeouts, proxies, custom TLS configuration or a test transport. A nil |hc| is ignored.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.client = hc
+		}
+	}
+}
+
+// WithBaseURL configures the Client to make requests to a different base URL. See SetBaseURL for more.
+// If |u| cannot be parsed, the error is returned from every subsequent request made by the Client.
+func WithBaseURL(u string) Option {
+	return func(c *Client) {
+		if err := c.SetBaseURL(u); err != nil && c.err == nil {
+			c.err = err
+		}
+	}
+}
+
+// WithOrganization configures the Client to send the "OpenAI-Organization" header with every request.
+func WithOrganization(org string) Option {
+	return func(c *Client) {
+		c.orgID = &org
+	}
+}
+
+// WithProject configures the Client to send the "OpenAI-Project" header with every request.
+func WithProject(project string) Option {
+	return func(c *Client) {
+		c.projectID = &project
+	}
+}
+
+// WithUserAgent configures the Client to send |ua| as the "User-Agent" header with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithDefaultHeaders configures the Client to send |h| with every request. Headers set by the Client itself
+// (e.g. "Authorization" or "Content-Type") take precedence over those in |h|. Multiple calls are merged.
+func WithDefaultHeaders(h http.Header) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header, len(h))
+		}
+		for k, v := range h {
+			c.headers[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+}
+
+// WithRequestValidation configures the Client to validate every request before sending it, by calling the request's
+// Validate method. Invalid requests are not sent; instead, a *ValidationError listing every invalid field is returned.
+func WithRequestValidation() Option {
+	return func(c *Client) {
+		c.validateRequests = true
+	}
+}
@@ -0,0 +1,218 @@
+package openai
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the default upper bounds, in seconds, of the histograms of PrometheusMetrics.
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// PrometheusMetrics implements Metrics, and serves the recorded metrics in the Prometheus text exposition format as an
+// http.Handler. The zero value is ready to use. The following metrics are recorded, labelled by model and route:
+//
+//   - {namespace}_requests_total: the number of requests, also labelled by HTTP status ("" if none was received).
+//   - {namespace}_errors_total: the number of failed requests, also labelled by error type and code.
+//   - {namespace}_request_duration_seconds: a histogram of the latency of requests.
+//   - {namespace}_tokens_total: the number of tokens used, also labelled by type ("input" or "output").
+//   - {namespace}_stream_duration_seconds: a histogram of the duration of streams.
+//   - {namespace}_stream_time_to_first_event_seconds: a histogram of the time to the first event of streams.
+type PrometheusMetrics struct {
+	// Namespace prefixes the name of each metric.
+	// Defaults to "openai".
+	Namespace string
+	// Buckets are the upper bounds, in seconds, of the histograms. They must not be modified after the first call.
+	// Defaults to DefaultBuckets.
+	Buckets []float64
+
+	once     sync.Once
+	mu       sync.Mutex
+	families []*metricFamily
+
+	requests, errors, tokens                   *metricFamily
+	duration, streamDuration, timeToFirstEvent *metricFamily
+}
+
+// ObserveCall implements Metrics.
+func (p *PrometheusMetrics) ObserveCall(m *CallMetrics) {
+	p.once.Do(p.init)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var status string
+	if m.StatusCode != 0 {
+		status = strconv.Itoa(m.StatusCode)
+	}
+	p.requests.with(m.Model, m.Route, status).value++
+
+	if m.Err != nil {
+		p.errors.with(m.Model, m.Route, m.ErrorType, m.ErrorCode).value++
+	}
+
+	p.duration.with(m.Model, m.Route).observe(m.Latency.Seconds(), p.Buckets)
+
+	if m.Usage != nil {
+		p.tokens.with(m.Model, m.Route, "input").value += float64(m.Usage.PromptTokens)
+		p.tokens.with(m.Model, m.Route, "output").value += float64(m.Usage.CompletionTokens)
+	}
+
+	if m.Stream && m.StreamDuration > 0 {
+		p.streamDuration.with(m.Model, m.Route).observe(m.StreamDuration.Seconds(), p.Buckets)
+	}
+	if m.Stream && m.TimeToFirstEvent > 0 {
+		p.timeToFirstEvent.with(m.Model, m.Route).observe(m.TimeToFirstEvent.Seconds(), p.Buckets)
+	}
+}
+
+// ServeHTTP implements http.Handler, writing the recorded metrics in the Prometheus text exposition format.
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = p.Write(w)
+}
+
+// Write writes the recorded metrics to |w| in the Prometheus text exposition format.
+func (p *PrometheusMetrics) Write(w io.Writer) error {
+	p.once.Do(p.init)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var bw = bufio.NewWriter(w)
+	for _, f := range p.families {
+		f.write(bw, p.Buckets)
+	}
+
+	return bw.Flush()
+}
+
+func (p *PrometheusMetrics) init() {
+	if p.Namespace == "" {
+		p.Namespace = "openai"
+	}
+	if p.Buckets == nil {
+		p.Buckets = DefaultBuckets
+	}
+	p.Buckets = append([]float64(nil), p.Buckets...)
+	sort.Float64s(p.Buckets)
+
+	var family = func(name, help, kind string, labels ...string) *metricFamily {
+		var f = &metricFamily{
+			name:   p.Namespace + "_" + name,
+			help:   help,
+			kind:   kind,
+			labels: append([]string{"model", "route"}, labels...),
+			series: make(map[string]*metricSeries),
+		}
+		p.families = append(p.families, f)
+
+		return f
+	}
+
+	p.requests = family("requests_total", "Total number of API requests.", "counter", "status")
+	p.errors = family("errors_total", "Total number of failed API requests.", "counter", "type", "code")
+	p.duration = family("request_duration_seconds", "Latency of API requests.", "histogram")
+	p.tokens = family("tokens_total", "Total number of tokens used by API requests.", "counter", "type")
+	p.streamDuration = family("stream_duration_seconds", "Duration of streamed API responses.", "histogram")
+	p.timeToFirstEvent = family("stream_time_to_first_event_seconds",
+		"Time to the first event of streamed API responses.", "histogram")
+}
+
+// metricFamily is a metric and its series, one per distinct set of label values.
+type metricFamily struct {
+	name, help, kind string
+	labels           []string
+	series           map[string]*metricSeries
+}
+
+// metricSeries holds the value of a counter, or the observations of a histogram.
+type metricSeries struct {
+	values []string
+	value  float64
+	// counts holds the number of observations in each bucket (not cumulatively), with one extra for +Inf.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// with returns the series with label |values|, creating it if necessary.
+func (f *metricFamily) with(values ...string) *metricSeries {
+	var key = strings.Join(values, "\xff")
+	var s, ok = f.series[key]
+	if !ok {
+		s = &metricSeries{values: values}
+		f.series[key] = s
+	}
+
+	return s
+}
+
+// observe records |v| in the histogram with upper bounds |buckets|.
+func (s *metricSeries) observe(v float64, buckets []float64) {
+	if s.counts == nil {
+		s.counts = make([]uint64, len(buckets)+1)
+	}
+	s.counts[sort.SearchFloat64s(buckets, v)]++
+	s.sum += v
+	s.count++
+}
+
+func (f *metricFamily) write(w *bufio.Writer, buckets []float64) {
+	if len(f.series) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+
+	var keys = make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		var s = f.series[k]
+		var labels = formatLabels(f.labels, s.values)
+		if f.kind != "histogram" {
+			_, _ = fmt.Fprintf(w, "%s{%s} %s\n", f.name, labels, formatFloat(s.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, b := range buckets {
+			cumulative += s.counts[i]
+			_, _ = fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", f.name, labels, formatFloat(b), cumulative)
+		}
+		_, _ = fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", f.name, labels, s.count)
+		_, _ = fmt.Fprintf(w, "%s_sum{%s} %s\n", f.name, labels, formatFloat(s.sum))
+		_, _ = fmt.Fprintf(w, "%s_count{%s} %d\n", f.name, labels, s.count)
+	}
+}
+
+// labelEscaper escapes label values as required by the Prometheus text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(names, values []string) string {
+	var b strings.Builder
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(n)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
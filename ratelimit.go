@@ -0,0 +1,280 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/tokenizer"
+)
+
+// RateLimit is the rate at which requests for a model may be sent. Zero values are unlimited.
+type RateLimit struct {
+	// RequestsPerMinute is the maximum number of requests per minute.
+	RequestsPerMinute int
+	// TokensPerMinute is the maximum number of tokens per minute. Each request reserves its estimated prompt tokens
+	// plus the maximum number of tokens it may generate.
+	TokensPerMinute int
+}
+
+// RateLimiter delays requests which would exceed OpenAI's per-model rate limits, rather than sending them and
+// receiving a 429. Capacity is replenished continuously. Before a request is sent, the RateLimiter reserves its
+// estimated tokens: the prompt is counted with the tokenizer package if the encoding is available, and approximated at
+// 4 bytes per token otherwise, to which MaxTokens (multiplied by N) is added; multipart uploads (e.g. audio) reserve
+// no tokens. The reservation is reconciled with the Usage of the response, which streams are requested to report.
+// Each retry of a request (see WithRetryPolicy) waits for, and consumes, another request of capacity.
+//
+// The RateLimiter tunes itself from the "x-ratelimit-limit-*" and "x-ratelimit-remaining-*" headers of every response,
+// so that it adopts the limits the API reports even for models without a configured RateLimit, and accounts for
+// requests made by other processes sharing the same API key.
+//
+// A RateLimiter may be shared by several Clients, and must not be copied after first use.
+type RateLimiter struct {
+	// Limits maps model IDs to their RateLimit.
+	Limits map[string]RateLimit
+	// Default is the RateLimit of models which are not in Limits.
+	Default RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*rateBuckets
+	// now returns the current time. Defaults to time.Now.
+	now func() time.Time
+}
+
+// WithRateLimiter configures the Client to wait for capacity from |l| before sending each request. Requests wait
+// until capacity is available or their context is done.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// rateBuckets holds the request and token buckets of a single model.
+type rateBuckets struct {
+	requests, tokens rateBucket
+}
+
+// rateBucket is a token bucket which refills at |capacity| per minute.
+type rateBucket struct {
+	capacity  float64
+	available float64
+	last      time.Time
+}
+
+func newRateBucket(capacity int, now time.Time) rateBucket {
+	return rateBucket{capacity: float64(capacity), available: float64(capacity), last: now}
+}
+
+// refill adds the capacity replenished since the bucket was last refilled.
+func (b *rateBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.available = math.Min(b.capacity, b.available+b.capacity*elapsed.Minutes())
+	}
+	b.last = now
+}
+
+// wait returns how long until |n| is available. Requests for more than the capacity need only wait for a full bucket.
+func (b *rateBucket) wait(n float64) time.Duration {
+	if b.capacity == 0 {
+		return 0
+	}
+
+	var missing = math.Min(n, b.capacity) - b.available
+	if missing <= 0 {
+		return 0
+	}
+
+	return time.Duration(missing / b.capacity * float64(time.Minute))
+}
+
+// tune adopts the |limit| and |remaining| capacity reported by the API, if set.
+func (b *rateBucket) tune(limit, remaining string) {
+	if n, err := strconv.Atoi(limit); err == nil && n > 0 {
+		// A previously unlimited bucket starts full.
+		if b.capacity == 0 {
+			b.available = float64(n)
+		}
+		b.capacity = float64(n)
+		b.available = math.Min(b.available, b.capacity)
+	}
+	if n, err := strconv.Atoi(remaining); err == nil && b.capacity > 0 {
+		b.available = math.Min(b.available, float64(n))
+	}
+}
+
+func (l *RateLimiter) time() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+
+	return time.Now()
+}
+
+// bucketsFor returns the buckets of |model|. l.mu must be held.
+func (l *RateLimiter) bucketsFor(model string, now time.Time) *rateBuckets {
+	if l.buckets == nil {
+		l.buckets = make(map[string]*rateBuckets)
+	}
+
+	var b, ok = l.buckets[model]
+	if !ok {
+		var limit, configured = l.Limits[model]
+		if !configured {
+			limit = l.Default
+		}
+		b = &rateBuckets{
+			requests: newRateBucket(limit.RequestsPerMinute, now),
+			tokens:   newRateBucket(limit.TokensPerMinute, now),
+		}
+		l.buckets[model] = b
+	}
+
+	return b
+}
+
+// reserve waits until there is capacity for |r|, the JSON encoding of |payload|, and reserves it. The returned func
+// reconciles the reservation with the Usage of the response. reserve is a no-op if |l| is nil.
+func (l *RateLimiter) reserve(ctx context.Context, payload any, r *request) (func(*Usage), error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	var tokens = float64(estimatedTokens(payload, r.body))
+	if err := l.acquire(ctx, r.model, tokens); err != nil {
+		return nil, err
+	}
+	r.limited = true
+
+	return func(u *Usage) {
+		if u == nil {
+			return
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		var b = l.bucketsFor(r.model, l.time())
+		if b.tokens.capacity > 0 {
+			b.tokens.available = math.Min(b.tokens.capacity, b.tokens.available+tokens-float64(u.TotalTokens))
+		}
+	}, nil
+}
+
+// acquire waits until there is capacity for a request for |model| which consumes |tokens|, and reserves it.
+func (l *RateLimiter) acquire(ctx context.Context, model string, tokens float64) error {
+	for {
+		l.mu.Lock()
+		var now = l.time()
+		var b = l.bucketsFor(model, now)
+		b.requests.refill(now)
+		b.tokens.refill(now)
+
+		var delay = b.requests.wait(1)
+		if d := b.tokens.wait(tokens); d > delay {
+			delay = d
+		}
+		if delay == 0 {
+			if b.requests.capacity > 0 {
+				b.requests.available--
+			}
+			if b.tokens.capacity > 0 {
+				b.tokens.available -= tokens
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		var t = time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// observe tunes the buckets of |model| from the rate limit headers of a response. observe is a no-op if |l| is nil.
+func (l *RateLimiter) observe(model string, h http.Header) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var now = l.time()
+	var b = l.bucketsFor(model, now)
+	b.requests.refill(now)
+	b.tokens.refill(now)
+	b.requests.tune(h.Get("x-ratelimit-limit-requests"), h.Get("x-ratelimit-remaining-requests"))
+	b.tokens.tune(h.Get("x-ratelimit-limit-tokens"), h.Get("x-ratelimit-remaining-tokens"))
+}
+
+// bytesPerToken approximates the number of bytes per token of English text, as the API does when enforcing rate
+// limits.
+const bytesPerToken = 4
+
+// estimatedTokens returns the number of tokens |payload|, JSON encoded as |body|, is expected to consume: its prompt
+// plus the maximum number of tokens it may generate.
+func estimatedTokens(payload any, body []byte) int {
+	switch p := payload.(type) {
+	case *streamingChatCompletion:
+		return estimatedTokens(p.ChatCompletionRequest, body)
+	case *streamingCompletion:
+		return estimatedTokens(p.CompletionRequest, body)
+	case *ChatCompletionRequest:
+		var prompt, err = CountChatTokens(p.Model, p.Messages, p.functions())
+		if err != nil {
+			prompt = len(body) / bytesPerToken
+		}
+
+		return prompt + p.MaxTokens*atLeastOne(p.N)
+	case *CompletionRequest[models.Completion]:
+		var maxTokens = p.MaxTokens
+		if maxTokens == 0 {
+			maxTokens = 16
+		}
+		var choices = atLeastOne(p.N)
+		if p.BestOf > choices {
+			choices = p.BestOf
+		}
+
+		return countTokens(p.Model, p.Prompt) + maxTokens*choices
+	case formWriter:
+		// Uploaded files (e.g. audio) don't count towards token limits.
+		return 0
+	case *EmbeddingRequest:
+		var n int
+		for _, in := range p.Input {
+			n += countTokens(p.Model, in)
+		}
+
+		return n
+	default:
+		return len(body) / bytesPerToken
+	}
+}
+
+// countTokens counts the tokens in |s| with the encoding of |model| if available, or approximates it otherwise.
+func countTokens(model fmt.Stringer, s string) int {
+	if enc, err := tokenizer.ForModel(model); err == nil {
+		return enc.Count(s)
+	}
+
+	return len(s) / bytesPerToken
+}
+
+func atLeastOne(n int) int {
+	if n < 1 {
+		return 1
+	}
+
+	return n
+}
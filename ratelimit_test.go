@@ -0,0 +1,143 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var requests int
+	var remainingTokens = "100000"
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("x-ratelimit-limit-tokens", "200000")
+		w.Header().Set("x-ratelimit-remaining-tokens", remainingTokens)
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[],` +
+			`"usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`))
+	}))
+	defer ts.Close()
+
+	var now = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	var l = &RateLimiter{
+		Limits: map[string]RateLimit{"gpt-4o-mini": {RequestsPerMinute: 2}},
+		now:    func() time.Time { return now },
+	}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithRateLimiter(l))
+
+	var create = func(timeout time.Duration) error {
+		var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var _, err = client.CreateChatCompletion(ctx, &ChatCompletionRequest{
+			Model:     models.GPT4oMini,
+			Messages:  []*ChatMessage{{Role: User, Content: "hi"}},
+			MaxTokens: 100,
+		})
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := create(time.Second); err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+	}
+	if err := create(10 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected request to wait for capacity, got %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests to be sent, got %d", requests)
+	}
+
+	// Half a minute replenishes one request.
+	now = now.Add(30 * time.Second)
+	if err := create(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The token limit is learned from the response headers.
+	l.mu.Lock()
+	var b = l.buckets["gpt-4o-mini"]
+	var capacity, available = b.tokens.capacity, b.tokens.available
+	l.mu.Unlock()
+	// The remaining tokens reported by the API are topped up with the unused part of the request's reservation.
+	if capacity != 200000 || available < 100000 || available > 101000 {
+		t.Errorf("expected tokens to be tuned to ~100000 of 200000, got %v of %v", available, capacity)
+	}
+
+	now = now.Add(time.Minute)
+	remainingTokens = "0"
+	if err := create(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := create(10 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected request to wait for token capacity, got %v", err)
+	}
+}
+
+func TestRateBucketReconcile(t *testing.T) {
+	var now = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	var l = &RateLimiter{Default: RateLimit{TokensPerMinute: 1000}, now: func() time.Time { return now }}
+
+	var reconcile, err = l.reserve(context.Background(), nil, &request{model: "m", body: make([]byte, 2000)})
+	if err != nil {
+		t.Fatalf("reserve error: %v", err)
+	}
+	if got := l.buckets["m"].tokens.available; got != 500 {
+		t.Fatalf("expected 500 tokens to remain after reserving, got %v", got)
+	}
+
+	reconcile(&Usage{TotalTokens: 100})
+	if got := l.buckets["m"].tokens.available; got != 900 {
+		t.Errorf("expected 900 tokens to remain after reconciling, got %v", got)
+	}
+}
+
+func TestRateLimiterRetries(t *testing.T) {
+	var requests int
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[]}`))
+	}))
+	defer ts.Close()
+
+	var now = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	var l = &RateLimiter{
+		Limits: map[string]RateLimit{"gpt-4o-mini": {RequestsPerMinute: 2}},
+		now:    func() time.Time { return now },
+	}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithRateLimiter(l),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+	var create = func(timeout time.Duration) error {
+		var ctx, cancel = context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var _, err = client.CreateChatCompletion(ctx, &ChatCompletionRequest{
+			Model:    models.GPT4oMini,
+			Messages: []*ChatMessage{{Role: User, Content: "hi"}},
+		})
+		return err
+	}
+
+	if err := create(time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected the request to be retried once, got %d requests", requests)
+	}
+
+	// The retry used the second request of the minute.
+	if err := create(10 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected request to wait for capacity, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how a Client retries failed requests. Requests are retried if the API returns an *Error for
+// which Retryable returns true, or if a transient network error occurs. Streaming requests are only retried while
+// establishing the initial connection; once events have begun to be received, errors are returned to the caller.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts made for a single request, including the first.
+	// Values <= 1 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry. Each subsequent retry doubles the previous delay.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. If the API indicates (via the "Retry-After" or
+	// "x-ratelimit-reset-*" headers) that a request should not be retried until after MaxDelay, the request is not
+	// retried. A zero value means no cap.
+	MaxDelay time.Duration
+	// Jitter is the fraction (between 0 and 1) of each computed backoff delay which is randomized. Jitter is not
+	// applied to delays requested by the API.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns a RetryPolicy suitable for most uses: up to 4 attempts, starting with a 500ms delay,
+// capped at 30s, with 20% jitter.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// WithRetryPolicy configures the Client to retry failed requests according to |p|. By default, requests are not
+// retried.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// next reports whether the request which resulted in |resp| and |err| should be retried and, if so, how long to wait
+// before doing so. |attempt| is the 1-indexed number of the attempt which just completed. |resp| may be nil.
+func (p *RetryPolicy) next(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !shouldRetry(err) {
+		return 0, false
+	}
+
+	if resp != nil {
+		if hint, ok := retryHint(resp); ok {
+			if p.MaxDelay > 0 && hint > p.MaxDelay {
+				return 0, false
+			}
+			return hint, true
+		}
+	}
+
+	return p.backoff(attempt), true
+}
+
+// backoff returns the exponential backoff delay following |attempt|.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	var d = float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		var j = math.Min(p.Jitter, 1)
+		d -= d * j * rand.Float64() //nolint:gosec // Jitter does not need to be cryptographically secure.
+	}
+
+	return time.Duration(d)
+}
+
+// shouldRetry reports whether |err| represents a transient failure.
+func shouldRetry(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryHint returns the delay requested by the API via response headers, if any. "retry-after-ms" and "Retry-After"
+// are preferred. Otherwise, on a 429, the reset time of whichever rate limit has been exhausted is used.
+func retryHint(resp *http.Response) (time.Duration, bool) {
+	if v := resp.Header.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if s, err := strconv.ParseFloat(v, 64); err == nil && s >= 0 {
+			return time.Duration(s * float64(time.Second)), true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			var d = time.Until(t)
+			if d < 0 {
+				d = 0
+			}
+			return d, true
+		}
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	var hint time.Duration
+	var ok bool
+	for _, kind := range []string{"requests", "tokens"} {
+		if resp.Header.Get("x-ratelimit-remaining-"+kind) != "0" {
+			continue
+		}
+		if d, err := time.ParseDuration(resp.Header.Get("x-ratelimit-reset-" + kind)); err == nil && d >= hint {
+			hint, ok = d, true
+		}
+	}
+
+	return hint, ok
+}
@@ -20,6 +20,10 @@ const (
 	// Deprecated: Use Models instead.
 	Engines = "engines"
 
+	// Models is the route for the models endpoint.
+	// https://platform.openai.com/docs/api-reference/models
+	Models = "models"
+
 	// Files is the route for the files endpoint.
 	// https://platform.openai.com/docs/api-reference/files
 	Files = "files"
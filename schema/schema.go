@@ -0,0 +1,495 @@
+// Package schema generates JSON Schema (https://json-schema.org/) documents from Go types, for use as function
+// parameters and structured output formats.
+package schema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema document. Only the subset of JSON Schema supported by the OpenAI API is represented.
+type Schema struct {
+	// Ref is a reference to another schema: either "#" (the root schema) or "#/$defs/{name}".
+	Ref string `json:"$ref,omitempty"`
+	// Type is the JSON type of the value: "object", "array", "string", "number", "integer", "boolean" or "null".
+	// Empty if any value is allowed, or if AnyOf or Ref is set.
+	Type string `json:"type,omitempty"`
+	// Description describes the value.
+	Description string `json:"description,omitempty"`
+	// Format is the format of a "string" value, e.g. "date-time".
+	Format string `json:"format,omitempty"`
+	// Enum restricts the value to a fixed set.
+	Enum []any `json:"enum,omitempty"`
+	// Properties are the properties of an "object" value, in declaration order.
+	Properties Properties `json:"properties,omitempty"`
+	// Required lists the properties of an "object" value which must be present, in declaration order.
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is either a bool, or a *Schema describing the values of properties not listed in
+	// Properties.
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+	// Items describes the elements of an "array" value.
+	Items *Schema `json:"items,omitempty"`
+	// AnyOf requires the value to match at least one of the listed schemas.
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	// Defs contains schemas referenced by Ref. Only set on the root schema.
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Property is a named property of an "object" Schema.
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are the properties of an "object" Schema. They are encoded as a JSON object whose keys are in the same
+// order as the Properties, since the API generates structured outputs with keys in schema order.
+type Properties []*Property
+
+// Get returns the Schema of the property named |name|, if any.
+func (p Properties) Get(name string) (*Schema, bool) {
+	for _, pp := range p {
+		if pp.Name == name {
+			return pp.Schema, true
+		}
+	}
+
+	return nil, false
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, pp := range p {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		var name, err = json.Marshal(pp.Name)
+		if err != nil {
+			return nil, err
+		}
+		var s []byte
+		if s, err = json.Marshal(pp.Schema); err != nil {
+			return nil, err
+		}
+
+		b.Write(name)
+		b.WriteByte(':')
+		b.Write(s)
+	}
+	b.WriteByte('}')
+
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, preserving the order of the properties.
+func (p *Properties) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		*p = nil
+		return nil
+	}
+
+	var dec = json.NewDecoder(bytes.NewReader(b))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("schema: properties must be an object, got %v", tok)
+	}
+
+	var out Properties
+	for dec.More() {
+		var tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var pp = &Property{Name: fmt.Sprint(tok), Schema: &Schema{}}
+		if err = dec.Decode(pp.Schema); err != nil {
+			return err
+		}
+		out = append(out, pp)
+	}
+	*p = out
+
+	return nil
+}
+
+// Enumer may be implemented by types whose values are restricted to a fixed set. Enum should return every valid value,
+// as it would be encoded by encoding/json. It is called on the zero value of the type.
+type Enumer interface {
+	Enum() []any
+}
+
+// Describer may be implemented by types to provide a description of the type. Descriptions provided by the
+// "description" struct tag take precedence.
+type Describer interface {
+	Description() string
+}
+
+// Reflector generates *Schemas from Go types.
+//
+// Struct fields are mapped to properties using the same rules as encoding/json, including the "json" struct tag and
+// embedded structs. Properties are required unless the field is a pointer or is tagged "omitempty". Descriptions are
+// read from the "description" struct tag. Recursive types are supported via "$ref".
+type Reflector struct {
+	// Strict generates schemas compatible with OpenAI's strict mode for function calling and structured outputs:
+	// every property is required (optional properties instead accept null), and no object allows additional
+	// properties. Maps are not supported in strict mode.
+	Strict bool
+}
+
+// For returns the *Schema for T.
+func For[T any]() (*Schema, error) {
+	return (&Reflector{}).Reflect(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// StrictFor returns the *Schema for T, generated in strict mode. See Reflector.Strict.
+func StrictFor[T any]() (*Schema, error) {
+	return (&Reflector{Strict: true}).Reflect(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// MustFor is like For, but panics on error. It simplifies initialization of package level variables.
+func MustFor[T any]() *Schema {
+	var s, err = For[T]()
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// Raw returns |s| encoded as JSON, e.g. for use as a Function's Parameters.
+func (s *Schema) Raw() (json.RawMessage, error) {
+	return json.Marshal(s)
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	enumerType      = reflect.TypeOf((*Enumer)(nil)).Elem()
+	describerType   = reflect.TypeOf((*Describer)(nil)).Elem()
+	textMarshalType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Reflect returns the *Schema for |t|.
+func (r *Reflector) Reflect(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var g = &generator{
+		strict:    r.Strict,
+		root:      t,
+		recursive: make(map[reflect.Type]bool),
+		defs:      make(map[string]*Schema),
+		names:     make(map[reflect.Type]string),
+	}
+	g.findRecursive(t, map[reflect.Type]bool{})
+
+	var s, err = g.schema(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+
+	return s, nil
+}
+
+type generator struct {
+	strict bool
+	root   reflect.Type
+	// recursive contains the struct types which (directly or indirectly) contain themselves.
+	recursive map[reflect.Type]bool
+	defs      map[string]*Schema
+	names     map[reflect.Type]string
+}
+
+// findRecursive populates g.recursive with every recursive struct type reachable from |t|. |stack| contains the struct
+// types currently being visited.
+func (g *generator) findRecursive(t reflect.Type, stack map[reflect.Type]bool) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		g.findRecursive(t.Elem(), stack)
+	case reflect.Struct:
+		if stack[t] {
+			g.recursive[t] = true
+			return
+		}
+		if t == timeType {
+			return
+		}
+
+		stack[t] = true
+		for _, f := range fields(t) {
+			g.findRecursive(f.typ, stack)
+		}
+		delete(stack, t)
+	default:
+		// Scalars cannot be recursive.
+	}
+}
+
+func (g *generator) schema(t reflect.Type, visited map[reflect.Type]bool) (*Schema, error) {
+	if t.Kind() == reflect.Pointer {
+		return g.schema(t.Elem(), visited)
+	}
+
+	var s, err = g.base(t, visited)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Ref == "" {
+		if reflect.PointerTo(t).Implements(enumerType) || t.Implements(enumerType) {
+			s.Enum = reflect.New(t).Interface().(Enumer).Enum()
+		}
+
+		if reflect.PointerTo(t).Implements(describerType) || t.Implements(describerType) {
+			s.Description = reflect.New(t).Interface().(Describer).Description()
+		}
+	}
+
+	return s, nil
+}
+
+func (g *generator) base(t reflect.Type, visited map[reflect.Type]bool) (*Schema, error) {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case rawMessageType:
+		return &Schema{}, nil
+	default:
+		// Handled below.
+	}
+
+	// Types which encode themselves as text (and aren't enums of another kind) are strings.
+	if t.Kind() != reflect.String && (t.Implements(textMarshalType) || reflect.PointerTo(t).Implements(textMarshalType)) {
+		return &Schema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		// []byte is encoded as a base64 string by encoding/json.
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}, nil
+		}
+
+		var items, err = g.schema(t.Elem(), visited)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if g.strict {
+			return nil, fmt.Errorf("schema: maps are not supported in strict mode: %s", t)
+		}
+		if k := t.Key().Kind(); k != reflect.String && !t.Key().Implements(textMarshalType) &&
+			(k < reflect.Int || k > reflect.Uint64) {
+			return nil, fmt.Errorf("schema: unsupported map key type: %s", t.Key())
+		}
+
+		var values, err = g.schema(t.Elem(), visited)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.object(t, visited)
+	default:
+		return nil, fmt.Errorf("schema: unsupported type: %s", t)
+	}
+}
+
+func (g *generator) object(t reflect.Type, visited map[reflect.Type]bool) (*Schema, error) {
+	if g.recursive[t] {
+		if t == g.root && visited[t] {
+			return &Schema{Ref: "#"}, nil
+		}
+		if t != g.root {
+			var name = g.defName(t)
+			if _, ok := g.defs[name]; !ok {
+				// Reserve the name before recursing so that nested references don't regenerate the definition.
+				g.defs[name] = nil
+
+				var s, err = g.fields(t, visited)
+				if err != nil {
+					return nil, err
+				}
+				g.defs[name] = s
+			}
+
+			return &Schema{Ref: "#/$defs/" + name}, nil
+		}
+	}
+
+	return g.fields(t, visited)
+}
+
+func (g *generator) fields(t reflect.Type, visited map[reflect.Type]bool) (*Schema, error) {
+	visited[t] = true
+	defer delete(visited, t)
+
+	var s = &Schema{
+		Type: "object",
+	}
+	if g.strict {
+		s.AdditionalProperties = false
+	}
+
+	for _, f := range fields(t) {
+		var ps, err = g.schema(f.typ, visited)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, f.goName, err)
+		}
+
+		if f.asString {
+			ps = &Schema{Type: "string"}
+		}
+		if f.description != "" {
+			if ps.Ref != "" {
+				ps = &Schema{AnyOf: []*Schema{ps}}
+			}
+			ps.Description = f.description
+		}
+
+		switch {
+		case !f.optional:
+			s.Required = append(s.Required, f.name)
+		case g.strict:
+			// Strict mode requires every property, so optional properties must instead accept null.
+			var desc = ps.Description
+			ps.Description = ""
+			ps = &Schema{AnyOf: []*Schema{ps, {Type: "null"}}, Description: desc}
+			s.Required = append(s.Required, f.name)
+		default:
+			// Optional properties are omitted from Required.
+		}
+
+		s.Properties = append(s.Properties, &Property{Name: f.name, Schema: ps})
+	}
+
+	return s, nil
+}
+
+// defName returns the name under which |t| is stored in $defs.
+func (g *generator) defName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	var name = t.Name()
+	if name == "" {
+		name = "Anonymous"
+	}
+	for i := 2; ; i++ {
+		var taken bool
+		for _, n := range g.names {
+			if n == name {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			break
+		}
+		name = fmt.Sprintf("%s%d", t.Name(), i)
+	}
+	g.names[t] = name
+
+	return name
+}
+
+type field struct {
+	name, goName, description string
+	typ                       reflect.Type
+	optional, asString        bool
+}
+
+// fields returns the fields of struct type |t| which are encoded by encoding/json, flattening embedded structs.
+// Unlike encoding/json, conflicting names are resolved in favor of the first field.
+func fields(t reflect.Type) []*field {
+	var out []*field
+	var seen = make(map[string]bool)
+
+	for i := 0; i < t.NumField(); i++ {
+		var sf = t.Field(i)
+		var tag = sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		var name, opts, _ = strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			var et = sf.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				for _, f := range fields(et) {
+					if !seen[f.name] {
+						seen[f.name] = true
+						out = append(out, f)
+					}
+				}
+				continue
+			}
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var f = &field{
+			name:        name,
+			goName:      sf.Name,
+			description: sf.Tag.Get("description"),
+			typ:         sf.Type,
+			optional:    sf.Type.Kind() == reflect.Pointer,
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				f.optional = true
+			case "string":
+				f.asString = true
+			default:
+				// Other options don't affect the schema.
+			}
+		}
+
+		out = append(out, f)
+	}
+
+	return out
+}
@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type unit string
+
+func (unit) Enum() []any {
+	return []any{"celsius", "fahrenheit"}
+}
+
+type location struct {
+	City    string `json:"city" description:"The city, e.g. San Francisco."`
+	Country string `json:"country,omitempty"`
+}
+
+type base struct {
+	ID int `json:"id"`
+}
+
+type weatherRequest struct {
+	base
+	Location  location          `json:"location"`
+	Unit      *unit             `json:"unit"`
+	Days      []int             `json:"days"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	At        time.Time         `json:"at"`
+	Count     int64             `json:"count,string"`
+	Raw       json.RawMessage   `json:"raw"`
+	Ignored   string            `json:"-"`
+	NoTag     bool
+	unexposed string
+}
+
+type node struct {
+	Value    string  `json:"value"`
+	Children []*node `json:"children"`
+}
+
+type tree struct {
+	Root *node `json:"root" description:"The root node."`
+}
+
+func TestFor(t *testing.T) {
+	var tcs = []struct {
+		name string
+		gen  func() (*Schema, error)
+		want string
+	}{
+		{
+			name: "struct",
+			gen:  For[weatherRequest],
+			want: `{"type":"object","properties":{` +
+				`"id":{"type":"integer"},` +
+				`"location":{"type":"object","properties":{"city":{"type":"string","description":"The city, e.g. San Francisco."},"country":{"type":"string"}},"required":["city"]},` +
+				`"unit":{"type":"string","enum":["celsius","fahrenheit"]},` +
+				`"days":{"type":"array","items":{"type":"integer"}},` +
+				`"tags":{"type":"object","additionalProperties":{"type":"string"}},` +
+				`"at":{"type":"string","format":"date-time"},` +
+				`"count":{"type":"string"},` +
+				`"raw":{},` +
+				`"NoTag":{"type":"boolean"}},` +
+				`"required":["id","location","days","at","count","raw","NoTag"]}`,
+		},
+		{
+			name: "recursive root",
+			gen:  For[node],
+			want: `{"type":"object","properties":{"value":{"type":"string"},"children":{"type":"array","items":{"$ref":"#"}}},"required":["value","children"]}`,
+		},
+		{
+			name: "recursive definition",
+			gen:  For[tree],
+			want: `{"type":"object","properties":{"root":{"description":"The root node.","anyOf":[{"$ref":"#/$defs/node"}]}},` +
+				`"$defs":{"node":{"type":"object","properties":{"value":{"type":"string"},"children":{"type":"array","items":{"$ref":"#/$defs/node"}}},"required":["value","children"]}}}`,
+		},
+		{
+			name: "strict",
+			gen:  StrictFor[location],
+			want: `{"type":"object","properties":{"city":{"type":"string","description":"The city, e.g. San Francisco."},"country":{"anyOf":[{"type":"string"},{"type":"null"}]}},"required":["city","country"],"additionalProperties":false}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var s, err = tc.gen()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var b []byte
+			if b, err = json.Marshal(s); err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tc.want {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.want, b)
+			}
+		})
+	}
+}
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	var in = `{"type":"object","properties":{"b":{"type":"string"},"a":{"type":"integer"}},"required":["b","a"]}`
+
+	var s = &Schema{}
+	if err := json.Unmarshal([]byte(in), s); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.Properties.Get("a"); !ok || s.Properties[0].Name != "b" {
+		t.Fatalf("expected properties in document order, got %+v", s.Properties)
+	}
+
+	var b, err = json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != in {
+		t.Errorf("expected:\n%s\ngot:\n%s", in, b)
+	}
+}
+
+func TestStrictForMap(t *testing.T) {
+	if _, err := StrictFor[weatherRequest](); err == nil {
+		t.Fatal("expected error for map in strict mode")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var s, err = StrictFor[tree]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tcs = []struct {
+		in    string
+		valid bool
+		path  string
+	}{
+		{in: `{"root":{"value":"a","children":[{"value":"b","children":[]}]}}`, valid: true},
+		{in: `{"root":null}`, valid: true},
+		{in: `{}`, path: ""},
+		{in: `{"root":null,"extra":1}`, path: ""},
+		// Violations within anyOf are reported at the anyOf.
+		{in: `{"root":{"value":"a","children":[{"value":1,"children":[]}]}}`, path: "/root"},
+	}
+
+	for _, tc := range tcs {
+		err = s.Validate([]byte(tc.in))
+		if tc.valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tc.in, err)
+			}
+			continue
+		}
+
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Path != tc.path {
+			t.Errorf("%s: expected *ValidationError at %q, got %v", tc.in, tc.path, err)
+		}
+	}
+}
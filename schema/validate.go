@@ -0,0 +1,198 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidationError describes a value which does not conform to a *Schema.
+type ValidationError struct {
+	// Path is the JSON Pointer (https://www.rfc-editor.org/rfc/rfc6901) to the offending value. Empty for the root.
+	Path string
+	// Message describes the violation.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return "schema: " + e.Message
+	}
+
+	return fmt.Sprintf("schema: %s: %s", e.Path, e.Message)
+}
+
+// Validate checks that the JSON document |data| conforms to |s|. It returns a *ValidationError describing the first
+// violation found, or an error if |data| is not valid JSON.
+func (s *Schema) Validate(data []byte) error {
+	var d = json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+
+	return (&validator{root: s}).validate(s, v, "")
+}
+
+type validator struct {
+	root *Schema
+}
+
+func (vr *validator) resolve(s *Schema) (*Schema, error) {
+	for s.Ref != "" {
+		switch {
+		case s.Ref == "#":
+			s = vr.root
+		case strings.HasPrefix(s.Ref, "#/$defs/"):
+			var def, ok = vr.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+			if !ok || def == nil {
+				return nil, fmt.Errorf("schema: unresolvable reference %q", s.Ref)
+			}
+			s = def
+		default:
+			return nil, fmt.Errorf("schema: unsupported reference %q", s.Ref)
+		}
+	}
+
+	return s, nil
+}
+
+func (vr *validator) validate(s *Schema, v any, path string) error {
+	var err error
+	if s, err = vr.resolve(s); err != nil {
+		return err
+	}
+
+	if len(s.AnyOf) > 0 {
+		var matched bool
+		for _, alt := range s.AnyOf {
+			if vr.validate(alt, v, path) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &ValidationError{Path: path, Message: "value does not match any allowed schema"}
+		}
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("value %v is not one of %v", v, s.Enum)}
+	}
+
+	if s.Type != "" && !hasType(s.Type, v) {
+		return &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", s.Type, typeOf(v))}
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		return vr.object(s, val, path)
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range val {
+			if err = vr.validate(s.Items, item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+	default:
+		// Scalars have been fully validated above.
+	}
+
+	return nil
+}
+
+func (vr *validator) object(s *Schema, val map[string]any, path string) error {
+	for _, name := range s.Required {
+		if _, ok := val[name]; !ok {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+		}
+	}
+
+	for name, pv := range val {
+		var p = path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+		if ps, ok := s.Properties.Get(name); ok {
+			if err := vr.validate(ps, pv, p); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch ap := s.AdditionalProperties.(type) {
+		case bool:
+			if !ap {
+				return &ValidationError{Path: path, Message: fmt.Sprintf("unexpected property %q", name)}
+			}
+		case *Schema:
+			if err := vr.validate(ap, pv, p); err != nil {
+				return err
+			}
+		default:
+			// Additional properties are allowed by default.
+		}
+	}
+
+	return nil
+}
+
+func hasType(t string, v any) bool {
+	switch t {
+	case "integer":
+		var n, ok = v.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := n.Int64(); err == nil {
+			return true
+		}
+		var f, err = n.Float64()
+		return err == nil && f == float64(int64(f))
+	default:
+		return typeOf(v) == t
+	}
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		// Compare via JSON so that e.g. int enum values match json.Numbers.
+		var eb, err = json.Marshal(e)
+		if err != nil {
+			continue
+		}
+
+		var d = json.NewDecoder(bytes.NewReader(eb))
+		d.UseNumber()
+
+		var ev any
+		if d.Decode(&ev) == nil && reflect.DeepEqual(ev, v) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,254 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// DefaultMaxEventSize is the default maximum size, in bytes, of a single server-sent event. See WithMaxEventSize.
+const DefaultMaxEventSize = 4 << 20
+
+// ErrEventTooLarge is returned while streaming if a single server-sent event exceeds the Client's maximum event size.
+var ErrEventTooLarge = errors.New("openai: server-sent event exceeds maximum size")
+
+// WithMaxEventSize configures the maximum size, in bytes, of a single server-sent event received while streaming.
+// Streams containing larger events fail with ErrEventTooLarge. Defaults to DefaultMaxEventSize.
+func WithMaxEventSize(n int) Option {
+	return func(c *Client) {
+		c.maxEventSize = n
+	}
+}
+
+const eof = "[DONE]"
+
+// event is a single server-sent event.
+type event struct {
+	// name is the value of the "event" field. Empty if the field was not set.
+	name string
+	// id is the value of the most recently received "id" field.
+	id   string
+	data []byte
+}
+
+// eventReader decodes server-sent events from a stream, as specified by
+// https://html.spec.whatwg.org/multipage/server-sent-events.html#event-stream-interpretation.
+type eventReader struct {
+	s      *bufio.Scanner
+	max    int
+	lastID string
+	first  bool
+}
+
+func newEventReader(r io.Reader, max int) *eventReader {
+	if max <= 0 {
+		max = DefaultMaxEventSize
+	}
+
+	var s = bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), max)
+	s.Split(scanLines)
+
+	return &eventReader{s: s, max: max, first: true}
+}
+
+// scanLines is a bufio.SplitFunc which splits on "\r\n", "\n" or "\r" (as required by the server-sent event spec),
+// unlike bufio.ScanLines which only recognizes "\n" and "\r\n".
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// A "\r" may be followed by a "\n" in the next read.
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// next returns the next event in the stream. It returns io.EOF when the stream ends; any partially received event is
+// discarded.
+func (r *eventReader) next() (*event, error) {
+	var data []byte
+	var name string
+	var hasData bool
+
+	for r.s.Scan() {
+		var line = r.s.Bytes()
+		if r.first {
+			line = bytes.TrimPrefix(line, []byte("\xEF\xBB\xBF"))
+			r.first = false
+		}
+
+		if len(line) == 0 {
+			if !hasData {
+				name = ""
+				continue
+			}
+
+			return &event{
+				name: name,
+				id:   r.lastID,
+				data: bytes.TrimSuffix(data, []byte("\n")),
+			}, nil
+		}
+
+		// Lines beginning with a colon are comments.
+		if line[0] == ':' {
+			continue
+		}
+
+		var field, value = line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], bytes.TrimPrefix(line[i+1:], []byte(" "))
+		}
+
+		switch string(field) {
+		case "data":
+			if len(data)+len(value)+1 > r.max {
+				return nil, ErrEventTooLarge
+			}
+			data = append(data, value...)
+			data = append(data, '\n')
+			hasData = true
+		case "event":
+			name = string(value)
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				r.lastID = string(value)
+			}
+		default:
+			// "retry" and unknown fields are ignored.
+		}
+	}
+
+	if err := r.s.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, ErrEventTooLarge
+		}
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// streamEvents returns two channels: the first is sent each event read from the Body of |res|'s Response, decoded as
+// JSON into a *T, and the second is sent any error encountered while receiving / parsing events. Both channels will be
+// closed on receipt of the "[DONE]" event, at the end of the stream, or upon the first encountered error. The Body is
+// closed once the stream ends. Each event, and the end of the stream, is reported to |res|'s OnEvent, including the
+// final chunk reporting the stream's Usage, which is only sent to the caller if they requested it.
+func streamEvents[T any](ctx context.Context, res *OperationResult, max int) (<-chan *T, <-chan error) {
+	var resps = make(chan *T)
+	var errCh = make(chan error, 1)
+
+	go func() {
+		var body = res.Response.Body
+		defer body.Close()
+		defer close(resps)
+		defer close(errCh)
+
+		var final error
+		if res.OnEvent != nil {
+			defer func() { res.OnEvent(nil, final) }()
+		}
+		// Streams which end without reporting their usage (e.g. because they failed) are recorded as such, so that
+		// their estimated cost is charged.
+		var recorded bool
+		if res.record != nil {
+			defer func() {
+				if !recorded {
+					res.record(nil)
+				}
+			}()
+		}
+		var fail = func(err error) {
+			final = err
+			errCh <- err
+		}
+
+		var r = newEventReader(body, max)
+		for {
+			var e, err = r.next()
+			switch {
+			case errors.Is(err, io.EOF):
+				return
+			case err != nil:
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				fail(err)
+				return
+			}
+
+			if string(e.data) == eof {
+				return
+			}
+
+			if err = eventError(e); err != nil {
+				fail(err)
+				return
+			}
+
+			var resp = new(T)
+			if err = json.Unmarshal(e.data, resp); err != nil {
+				fail(err)
+				return
+			}
+
+			var u *Usage
+			if ur, ok := any(resp).(usageReporter); ok {
+				u = ur.usage()
+			}
+			if u != nil && res.record != nil {
+				res.record(u)
+				recorded = true
+			}
+			if res.OnEvent != nil {
+				res.OnEvent(resp, nil)
+			}
+			if u != nil && res.hideUsage {
+				continue
+			}
+
+			select {
+			case resps <- resp:
+			case <-ctx.Done():
+				fail(ctx.Err())
+				return
+			}
+		}
+	}()
+
+	return resps, errCh
+}
+
+// eventError returns the *Error contained in |e|, if the API sent an error mid-stream.
+func eventError(e *event) error {
+	if e.name != "error" && !bytes.Contains(e.data, []byte(`"error"`)) {
+		return nil
+	}
+
+	var ret = &wrappedError{}
+	if json.Unmarshal(e.data, ret) != nil || ret.Err == nil {
+		return nil
+	}
+
+	return ret.Err
+}
@@ -0,0 +1,148 @@
+package openai
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEventReader(t *testing.T) {
+	var tcs = []struct {
+		name string
+		in   string
+		out  []event
+		err  error
+	}{
+		{
+			name: "single event",
+			in:   "data: {}\n\n",
+			out:  []event{{data: []byte(`{}`)}},
+		},
+		{
+			name: "unterminated event is discarded",
+			in:   "data: {}\n\ndata: {}",
+			out:  []event{{data: []byte(`{}`)}},
+		},
+		{
+			name: "line endings",
+			in:   "data: a\r\n\r\ndata: b\r\rdata: c\n\n",
+			out:  []event{{data: []byte("a")}, {data: []byte("b")}, {data: []byte("c")}},
+		},
+		{
+			name: "multi-line data",
+			in:   "data: a\ndata:b\ndata:  c\n\n",
+			out:  []event{{data: []byte("a\nb\n c")}},
+		},
+		{
+			name: "event and id fields",
+			in:   "event: error\nid: 1\ndata: x\n\ndata: y\n\n",
+			out:  []event{{name: "error", id: "1", data: []byte("x")}, {id: "1", data: []byte("y")}},
+		},
+		{
+			name: "comments, retry and unknown fields",
+			in:   ": ping\nretry: 100\nfoo: bar\ndata\n\n: ping\n\n",
+			out:  []event{{data: []byte("")}},
+		},
+		{
+			name: "byte order mark",
+			in:   "\xEF\xBB\xBFdata: a\n\n",
+			out:  []event{{data: []byte("a")}},
+		},
+		{
+			name: "too large",
+			in:   "data: " + strings.Repeat("a", 64) + "\n\n",
+			err:  ErrEventTooLarge,
+		},
+		{
+			name: "too large across lines",
+			in:   strings.Repeat("data: "+strings.Repeat("a", 20)+"\n", 4) + "\n",
+			err:  ErrEventTooLarge,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var out, err = readEvents(strings.NewReader(tc.in), 64)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected err=%v, got err=%v", tc.err, err)
+			}
+			if len(out) != len(tc.out) {
+				t.Fatalf("expected %d events, got %d", len(tc.out), len(out))
+			}
+			for i := range out {
+				if out[i].name != tc.out[i].name || out[i].id != tc.out[i].id || !bytes.Equal(out[i].data, tc.out[i].data) {
+					t.Errorf("event %d: expected %+v, got %+v", i, tc.out[i], out[i])
+				}
+			}
+		})
+	}
+}
+
+// FuzzEventReader asserts that the events decoded from a stream do not depend on how the stream is chunked.
+func FuzzEventReader(f *testing.F) {
+	f.Add([]byte("data: {}\n\ndata: [DONE]\n\n"), uint8(1))
+	f.Add([]byte("data: a\r\ndata: b\r\n\r\nevent: x\rid: 2\rdata: c\r\r"), uint8(3))
+	f.Add([]byte(": comment\n\ndata:\x00\n\n"), uint8(7))
+
+	f.Fuzz(func(t *testing.T, in []byte, chunk uint8) {
+		var want, wantErr = readEvents(bytes.NewReader(in), 1<<10)
+		var got, gotErr = readEvents(&chunkedReader{b: in, n: int(chunk%16) + 1}, 1<<10)
+		if !errors.Is(gotErr, wantErr) {
+			t.Fatalf("expected err=%v, got err=%v", wantErr, gotErr)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %d events, got %d", len(want), len(got))
+		}
+		for i := range got {
+			if got[i].name != want[i].name || got[i].id != want[i].id || !bytes.Equal(got[i].data, want[i].data) {
+				t.Fatalf("event %d: expected %+v, got %+v", i, want[i], got[i])
+			}
+		}
+	})
+}
+
+func TestEventReaderOneByte(t *testing.T) {
+	var out, err = readEvents(iotest.OneByteReader(strings.NewReader("data: a\r\n\r\ndata: b\r\r")), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || string(out[0].data) != "a" || string(out[1].data) != "b" {
+		t.Fatalf("unexpected events %+v", out)
+	}
+}
+
+func readEvents(r io.Reader, max int) ([]event, error) {
+	var er = newEventReader(r, max)
+	var out []event
+	for {
+		var e, err = er.next()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, *e)
+	}
+}
+
+// chunkedReader returns at most n bytes per Read.
+type chunkedReader struct {
+	b []byte
+	n int
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if len(c.b) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > c.n {
+		p = p[:c.n]
+	}
+	var n = copy(p, c.b)
+	c.b = c.b[n:]
+	return n, nil
+}
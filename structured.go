@@ -0,0 +1,119 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/fabiustech/openai/schema"
+)
+
+// ErrIncompleteResponse is returned by CreateStructured if the model stopped generating before completing its
+// response, e.g. because MaxTokens was reached or the content filter was triggered.
+var ErrIncompleteResponse = errors.New("openai: incomplete structured response")
+
+// RefusalError is returned by CreateStructured if the model refused to respond.
+type RefusalError struct {
+	// Refusal is the refusal message generated by the model.
+	Refusal string
+}
+
+// Error implements the error interface.
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("openai: model refused to respond: %s", e.Refusal)
+}
+
+// SchemaViolationError is returned by CreateStructured if the model's response does not match the requested schema.
+type SchemaViolationError struct {
+	// Content is the raw content generated by the model.
+	Content string
+	// Err describes the violation. It is either a *schema.ValidationError or an error from encoding/json.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *SchemaViolationError) Error() string {
+	return fmt.Sprintf("openai: response does not match schema: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *SchemaViolationError) Unwrap() error {
+	return e.Err
+}
+
+// CreateStructured creates a chat completion whose response is constrained to the JSON Schema generated from T (in
+// strict mode; see schema.StrictFor), and decodes the response into a *T. The schema is named after T. |cr| is not
+// modified; any ResponseFormat it specifies is ignored. Only the first choice is decoded.
+//
+// If the model refuses to respond, a *RefusalError is returned. If its response does not match the schema, a
+// *SchemaViolationError is returned. ErrIncompleteResponse is returned if the response was cut short. In all cases,
+// the *ChatCompletionResponse is returned if one was received.
+func CreateStructured[T any](ctx context.Context, c *Client, cr *ChatCompletionRequest) (*T, *ChatCompletionResponse, error) {
+	var s, err = schema.StrictFor[T]()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var raw json.RawMessage
+	if raw, err = s.Raw(); err != nil {
+		return nil, nil, err
+	}
+
+	var req = *cr
+	req.ResponseFormat = ResponseFormatJSONSchema(schemaName(reflect.TypeOf((*T)(nil)).Elem()), raw)
+
+	var resp *ChatCompletionResponse
+	if resp, err = c.CreateChatCompletion(ctx, &req); err != nil {
+		return nil, nil, err
+	}
+
+	if len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+		return nil, resp, errors.New("openai: chat completion response contained no message")
+	}
+
+	var choice = resp.Choices[0]
+	if choice.Message.Refusal != "" {
+		return nil, resp, &RefusalError{Refusal: choice.Message.Refusal}
+	}
+
+	switch choice.FinishReason {
+	case "length", "content_filter":
+		return nil, resp, fmt.Errorf("%w: finish reason %q", ErrIncompleteResponse, choice.FinishReason)
+	default:
+		// The response is complete.
+	}
+
+	var content = []byte(choice.Message.Content)
+	if err = s.Validate(content); err != nil {
+		return nil, resp, &SchemaViolationError{Content: choice.Message.Content, Err: err}
+	}
+
+	var out = new(T)
+	if err = json.Unmarshal(content, out); err != nil {
+		return nil, resp, &SchemaViolationError{Content: choice.Message.Content, Err: err}
+	}
+
+	return out, resp, nil
+}
+
+var invalidSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// schemaName returns a valid JSONSchema.Name for |t|.
+func schemaName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	var name = invalidSchemaNameChars.ReplaceAllString(t.Name(), "_")
+	if name == "" {
+		name = "response"
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return name
+}
@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/schema"
+)
+
+type recipe struct {
+	Name        string   `json:"name"`
+	Ingredients []string `json:"ingredients"`
+	Minutes     *int     `json:"minutes"`
+}
+
+func TestCreateStructured(t *testing.T) {
+	var tcs = []struct {
+		name string
+		msg  *ChatMessage
+		test func(t *testing.T, r *recipe, err error)
+	}{
+		{
+			name: "success",
+			msg:  &ChatMessage{Role: Assistant, Content: `{"name":"Toast","ingredients":["bread"],"minutes":null}`},
+			test: func(t *testing.T, r *recipe, err error) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Name != "Toast" || len(r.Ingredients) != 1 || r.Minutes != nil {
+					t.Fatalf("unexpected recipe %+v", r)
+				}
+			},
+		},
+		{
+			name: "refusal",
+			msg:  &ChatMessage{Role: Assistant, Refusal: "I can't help with that."},
+			test: func(t *testing.T, r *recipe, err error) {
+				var re *RefusalError
+				if !errors.As(err, &re) || re.Refusal != "I can't help with that." {
+					t.Fatalf("expected *RefusalError, got %v", err)
+				}
+			},
+		},
+		{
+			name: "schema violation",
+			msg:  &ChatMessage{Role: Assistant, Content: `{"name":"Toast","ingredients":"bread","minutes":1}`},
+			test: func(t *testing.T, r *recipe, err error) {
+				var se *SchemaViolationError
+				var ve *schema.ValidationError
+				if !errors.As(err, &se) || !errors.As(err, &ve) || ve.Path != "/ingredients" {
+					t.Fatalf("expected *SchemaViolationError, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req = &ChatCompletionRequest{}
+				if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+					http.Error(w, "could not read request", http.StatusBadRequest)
+					return
+				}
+				if f := req.ResponseFormat; f == nil || f.Type != ResponseFormatTypeJSONSchema ||
+					f.JSONSchema.Name != "recipe" || f.JSONSchema.Strict == nil || !*f.JSONSchema.Strict {
+					http.Error(w, "unexpected response format", http.StatusBadRequest)
+					return
+				}
+
+				var b, _ = json.Marshal(&ChatCompletionResponse{
+					Choices: []*ChatCompletionChoice{{Message: tc.msg, FinishReason: "stop"}},
+				})
+				_, _ = w.Write(b)
+			}))
+			defer ts.Close()
+
+			var client, _ = newTestClient(ts.URL)
+			var r, _, err = CreateStructured[recipe](context.Background(), client, &ChatCompletionRequest{
+				Model:    models.GPT4oMini,
+				Messages: []*ChatMessage{{Role: User, Content: "How do I make toast?"}},
+			})
+			tc.test(t, r, err)
+		})
+	}
+}
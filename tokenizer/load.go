@@ -0,0 +1,151 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DirEnv is the environment variable which names the directory from which rank files are loaded, unless SetSource is
+// called.
+const DirEnv = "OPENAI_TOKENIZER_DIR"
+
+// UnknownEncodingError is returned when an encoding name is not recognized.
+type UnknownEncodingError struct {
+	Name Name
+}
+
+// Error implements the error interface.
+func (e *UnknownEncodingError) Error() string {
+	return fmt.Sprintf("tokenizer: unknown encoding %q", e.Name)
+}
+
+// LoadRanks parses a rank file in the tiktoken format: one token per line, as the base64 encoded token bytes followed
+// by a space and the token's rank.
+func LoadRanks(r io.Reader) (map[string]int, error) {
+	var ranks = make(map[string]int)
+
+	var s = bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		var text = strings.TrimSpace(s.Text())
+		if text == "" {
+			continue
+		}
+
+		var tok, rank, ok = strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("tokenizer: malformed rank file at line %d", line)
+		}
+
+		var b, err = base64.StdEncoding.DecodeString(tok)
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: malformed token at line %d: %w", line, err)
+		}
+
+		var n int
+		if n, err = strconv.Atoi(rank); err != nil {
+			return nil, fmt.Errorf("tokenizer: malformed rank at line %d: %w", line, err)
+		}
+
+		ranks[string(b)] = n
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return ranks, nil
+}
+
+// Load returns the Encoding named |name|, whose rank file is read from |r|.
+func Load(name Name, r io.Reader) (*Encoding, error) {
+	if _, ok := specs[name]; !ok {
+		return nil, &UnknownEncodingError{Name: name}
+	}
+
+	var ranks, err = LoadRanks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(name, ranks)
+}
+
+// LoadFile returns the Encoding named |name|, whose rank file is read from the file at |path|.
+func LoadFile(name Name, path string) (*Encoding, error) {
+	var f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(name, f)
+}
+
+// LoadFS returns the Encoding named |name|, whose rank file is read from "<rank file name>.tiktoken" in |fsys|, e.g.
+// "cl100k_base.tiktoken". The p50k_edit encoding is read from "p50k_base.tiktoken".
+func LoadFS(fsys fs.FS, name Name) (*Encoding, error) {
+	var sp, ok = specs[name]
+	if !ok {
+		return nil, &UnknownEncodingError{Name: name}
+	}
+
+	var f, err = fsys.Open(string(sp.rankFile) + ".tiktoken")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(name, f)
+}
+
+var cache = struct {
+	sync.Mutex
+	source    fs.FS
+	encodings map[Name]*Encoding
+}{
+	encodings: make(map[Name]*Encoding),
+}
+
+// SetSource sets the file system from which Get loads rank files (see LoadFS), e.g. an embed.FS containing the files
+// required by an application. It clears any encodings previously loaded by Get.
+func SetSource(fsys fs.FS) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	cache.source = fsys
+	cache.encodings = make(map[Name]*Encoding)
+}
+
+// Get returns the Encoding named |name|, loading it on first use from the source set by SetSource or, if none was set,
+// from the directory named by the OPENAI_TOKENIZER_DIR environment variable. Loaded encodings are cached.
+func Get(name Name) (*Encoding, error) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	if e, ok := cache.encodings[name]; ok {
+		return e, nil
+	}
+
+	var fsys = cache.source
+	if fsys == nil {
+		var dir = os.Getenv(DirEnv)
+		if dir == "" {
+			return nil, fmt.Errorf("tokenizer: no source for encoding %q: call SetSource or set %s", name, DirEnv)
+		}
+		fsys = os.DirFS(dir)
+	}
+
+	var e, err = LoadFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	cache.encodings[name] = e
+
+	return e, nil
+}
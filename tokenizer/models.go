@@ -0,0 +1,81 @@
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fabiustech/openai/models"
+)
+
+// EncodingName returns the name of the encoding used by |model|, which must be a models.ChatCompletion,
+// models.Completion, models.Embedding or models.Edit.
+func EncodingName(model fmt.Stringer) (Name, error) {
+	switch m := model.(type) {
+	case models.ChatCompletion:
+		return chatCompletionEncoding(m)
+	case models.Completion:
+		return completionEncoding(m)
+	case models.Embedding:
+		return embeddingEncoding(m)
+	case models.Edit:
+		if m.String() == "" {
+			break
+		}
+		return P50kEdit, nil
+	}
+
+	return "", fmt.Errorf("tokenizer: no encoding for model %q", model.String())
+}
+
+// ForModel returns the Encoding used by |model|, loading it with Get. See EncodingName.
+func ForModel(model fmt.Stringer) (*Encoding, error) {
+	var name, err = EncodingName(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return Get(name)
+}
+
+func chatCompletionEncoding(m models.ChatCompletion) (Name, error) {
+	var s = m.String()
+	// Fine-tuned models ("ft:{base}:...") use the encoding of their base model.
+	if strings.HasPrefix(s, "ft:") {
+		s, _, _ = strings.Cut(strings.TrimPrefix(s, "ft:"), ":")
+	}
+
+	switch {
+	case strings.HasPrefix(s, "gpt-4o"), strings.HasPrefix(s, "o1"):
+		return O200kBase, nil
+	case strings.HasPrefix(s, "gpt-4"), strings.HasPrefix(s, "gpt-3.5-turbo"):
+		return CL100kBase, nil
+	default:
+		return "", fmt.Errorf("tokenizer: no encoding for chat completion model %q", s)
+	}
+}
+
+func completionEncoding(m models.Completion) (Name, error) {
+	switch m {
+	case models.TextDavinci003, models.TextDavinci002, models.CodeDavinci002, models.CodeCushman001,
+		models.CodeDavinci001, models.TextDavinciInsert002, models.TextDavinciInsert001:
+		return P50kBase, nil
+	case models.TextCurie001, models.TextBabbage001, models.TextAda001, models.TextDavinci001,
+		models.DavinciInstructBeta, models.CurieInstructBeta:
+		return R50kBase, nil
+	default:
+		return "", fmt.Errorf("tokenizer: no encoding for completion model %q", m.String())
+	}
+}
+
+func embeddingEncoding(m models.Embedding) (Name, error) {
+	var s = m.String()
+	switch {
+	case m == models.AdaEmbeddingV2:
+		return CL100kBase, nil
+	case strings.HasSuffix(s, "-001"):
+		// First-generation embedding models use the GPT-3 tokenizer.
+		return R50kBase, nil
+	default:
+		return "", fmt.Errorf("tokenizer: no encoding for embedding model %q", s)
+	}
+}
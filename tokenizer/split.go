@@ -0,0 +1,290 @@
+package tokenizer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// The functions in this file split text into the pieces which are then individually byte-pair encoded. They are
+// hand-written equivalents of the regular expressions used by OpenAI's tiktoken library, which rely on features
+// (e.g. negative lookahead) unsupported by Go's regexp package. Each function returns the length, in bytes, of the
+// piece beginning at the start of |s|, which must be non-empty.
+
+// splitGPT2 implements the r50k_base and p50k_base pattern:
+//
+//	's|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+
+func splitGPT2(s string) int {
+	if n := contraction(s, false); n > 0 {
+		return n
+	}
+
+	var j int
+	if s[0] == ' ' {
+		j = 1
+	}
+	if j < len(s) {
+		var r, _ = utf8.DecodeRuneInString(s[j:])
+		switch {
+		case isLetter(r):
+			return j + span(s[j:], isLetter)
+		case isNumber(r):
+			return j + span(s[j:], isNumber)
+		case isOther(r):
+			return j + span(s[j:], isOther)
+		default:
+			// Whitespace is handled below.
+		}
+	}
+
+	return whitespace(s)
+}
+
+// splitCL100k implements the cl100k_base pattern:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitCL100k(s string) int {
+	if n := contraction(s, true); n > 0 {
+		return n
+	}
+
+	var r, size = utf8.DecodeRuneInString(s)
+
+	// [^\r\n\p{L}\p{N}]?\p{L}+
+	if isLetter(r) {
+		return span(s, isLetter)
+	}
+	if isPrefix(r) && size < len(s) {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); isLetter(next) {
+			return size + span(s[size:], isLetter)
+		}
+	}
+
+	// \p{N}{1,3}
+	if isNumber(r) {
+		return spanN(s, isNumber, 3)
+	}
+
+	// ?[^\s\p{L}\p{N}]+[\r\n]*
+	if n := punctuation(s, isNewline); n > 0 {
+		return n
+	}
+
+	// \s*[\r\n]+
+	if n := newlines(s); n > 0 {
+		return n
+	}
+
+	return whitespace(s)
+}
+
+// splitO200k implements the o200k_base pattern:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitO200k(s string) int {
+	var r, size = utf8.DecodeRuneInString(s)
+
+	// Each of the first two alternatives is attempted with, then without, the optional prefix.
+	var starts = []int{0}
+	if isPrefix(r) {
+		starts = []int{size, 0}
+	}
+
+	for _, j := range starts {
+		if n := lowerWord(s[j:]); n > 0 {
+			return j + n + contraction(s[j+n:], true)
+		}
+	}
+	for _, j := range starts {
+		if n := span(s[j:], isUpper); n > 0 {
+			n += span(s[j+n:], isLower)
+			return j + n + contraction(s[j+n:], true)
+		}
+	}
+
+	// \p{N}{1,3}
+	if isNumber(r) {
+		return spanN(s, isNumber, 3)
+	}
+
+	// ?[^\s\p{L}\p{N}]+[\r\n/]*
+	if n := punctuation(s, func(r rune) bool { return isNewline(r) || r == '/' }); n > 0 {
+		return n
+	}
+
+	// \s*[\r\n]+
+	if n := newlines(s); n > 0 {
+		return n
+	}
+
+	return whitespace(s)
+}
+
+// lowerWord matches [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+, returning 0 if there is no match.
+func lowerWord(s string) int {
+	var u = span(s, isUpper)
+	if n := span(s[u:], isLower); n > 0 {
+		return u + n
+	}
+
+	// The greedy upper run must give back characters until the last of them which is also "lower" can start the
+	// lower run. Since the character following the upper run is not lower, the match ends immediately after it.
+	var end int
+	for i, r := range s[:u] {
+		if isLower(r) {
+			end = i + utf8.RuneLen(r)
+		}
+	}
+
+	return end
+}
+
+// contraction matches 's|'t|'re|'ve|'m|'ll|'d, case-insensitively if |fold| is true. It returns 0 if there is no
+// match.
+func contraction(s string, fold bool) int {
+	if len(s) < 2 || s[0] != '\'' {
+		return 0
+	}
+
+	var lower = func(r rune) rune {
+		if !fold {
+			return r
+		}
+		// Under Unicode simple case folding, "s" also matches U+017F LATIN SMALL LETTER LONG S.
+		if r == 'ſ' {
+			return 's'
+		}
+		return unicode.ToLower(r)
+	}
+
+	var r1, n1 = utf8.DecodeRuneInString(s[1:])
+
+	var want rune
+	switch lower(r1) {
+	case 's', 't', 'm', 'd':
+		return 1 + n1
+	case 'r', 'v':
+		want = 'e'
+	case 'l':
+		want = 'l'
+	default:
+		return 0
+	}
+
+	if 1+n1 < len(s) {
+		if r2, n2 := utf8.DecodeRuneInString(s[1+n1:]); lower(r2) == want {
+			return 1 + n1 + n2
+		}
+	}
+
+	return 0
+}
+
+// punctuation matches " ?[^\s\p{L}\p{N}]+" followed by any number of characters for which |trailing| returns true.
+// It returns 0 if there is no match.
+func punctuation(s string, trailing func(rune) bool) int {
+	var j int
+	if s[0] == ' ' {
+		j = 1
+	}
+
+	var n = span(s[j:], isOther)
+	if n == 0 {
+		return 0
+	}
+
+	return j + n + span(s[j+n:], trailing)
+}
+
+// newlines matches \s*[\r\n]+, returning 0 if there is no match.
+func newlines(s string) int {
+	var ws = span(s, unicode.IsSpace)
+
+	var end int
+	for i, r := range s[:ws] {
+		if isNewline(r) {
+			end = i + utf8.RuneLen(r)
+		}
+	}
+
+	return end
+}
+
+// whitespace matches \s+(?!\S)|\s+. If |s| does not begin with whitespace, it returns the length of the first rune so
+// that splitting always makes progress.
+func whitespace(s string) int {
+	var ws = span(s, unicode.IsSpace)
+	if ws == 0 {
+		var _, size = utf8.DecodeRuneInString(s)
+		return size
+	}
+	if ws == len(s) {
+		return ws
+	}
+
+	// The whitespace is followed by a non-space character, so the lookahead requires giving back the final whitespace
+	// character, unless doing so would leave nothing to match.
+	var _, last = utf8.DecodeLastRuneInString(s[:ws])
+	if ws-last > 0 {
+		return ws - last
+	}
+
+	return ws
+}
+
+// span returns the length of the longest prefix of |s| whose runes all satisfy |f|.
+func span(s string, f func(rune) bool) int {
+	for i, r := range s {
+		if !f(r) {
+			return i
+		}
+	}
+
+	return len(s)
+}
+
+// spanN is like span, but matches at most |max| runes.
+func spanN(s string, f func(rune) bool, max int) int {
+	var n int
+	for i, r := range s {
+		if n == max || !f(r) {
+			return i
+		}
+		n++
+	}
+
+	return len(s)
+}
+
+func isLetter(r rune) bool {
+	return unicode.IsLetter(r)
+}
+
+func isNumber(r rune) bool {
+	return unicode.IsNumber(r)
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+// isOther matches [^\s\p{L}\p{N}].
+func isOther(r rune) bool {
+	return !unicode.IsSpace(r) && !isLetter(r) && !isNumber(r)
+}
+
+// isPrefix matches [^\r\n\p{L}\p{N}].
+func isPrefix(r rune) bool {
+	return !isNewline(r) && !isLetter(r) && !isNumber(r)
+}
+
+// isUpper matches [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}].
+func isUpper(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+// isLower matches [\p{Ll}\p{Lm}\p{Lo}\p{M}].
+func isLower(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
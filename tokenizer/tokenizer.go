@@ -0,0 +1,264 @@
+// Package tokenizer implements the byte-pair encodings (BPE) used by OpenAI models, allowing tokens to be counted
+// client-side without calling the API. It is a zero-dependency port of the encoding logic in OpenAI's tiktoken
+// library (https://github.com/openai/tiktoken).
+//
+// The package does not bundle the (multi-megabyte) rank files which define each encoding. They must be downloaded
+// from https://openaipublic.blob.core.windows.net/encodings/{name}.tiktoken (e.g. cl100k_base.tiktoken) and made
+// available either by setting the OPENAI_TOKENIZER_DIR environment variable to the directory containing them, by
+// calling SetSource (e.g. with an embed.FS), or by loading them directly with Load.
+package tokenizer
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Name is the name of an encoding.
+type Name string
+
+const (
+	// R50kBase is the encoding used by GPT-3 models (e.g. text-curie-001) and first-generation embedding models.
+	R50kBase Name = "r50k_base"
+	// P50kBase is the encoding used by Codex models and text-davinci-002 / text-davinci-003.
+	P50kBase Name = "p50k_base"
+	// P50kEdit is the encoding used by the edit models. It shares P50kBase's rank file.
+	P50kEdit Name = "p50k_edit"
+	// CL100kBase is the encoding used by GPT-3.5 Turbo, GPT-4 and text-embedding-ada-002.
+	CL100kBase Name = "cl100k_base"
+	// O200kBase is the encoding used by GPT-4o and o1 models.
+	O200kBase Name = "o200k_base"
+)
+
+// Special tokens.
+const (
+	EndOfText   = "<|endoftext|>"
+	FIMPrefix   = "<|fim_prefix|>"
+	FIMMiddle   = "<|fim_middle|>"
+	FIMSuffix   = "<|fim_suffix|>"
+	EndOfPrompt = "<|endofprompt|>"
+)
+
+type spec struct {
+	rankFile Name
+	split    func(string) int
+	special  map[string]int
+}
+
+var specs = map[Name]*spec{
+	R50kBase: {
+		rankFile: R50kBase,
+		split:    splitGPT2,
+		special:  map[string]int{EndOfText: 50256},
+	},
+	P50kBase: {
+		rankFile: P50kBase,
+		split:    splitGPT2,
+		special:  map[string]int{EndOfText: 50256},
+	},
+	P50kEdit: {
+		rankFile: P50kBase,
+		split:    splitGPT2,
+		special:  map[string]int{EndOfText: 50256, FIMPrefix: 50281, FIMMiddle: 50282, FIMSuffix: 50283},
+	},
+	CL100kBase: {
+		rankFile: CL100kBase,
+		split:    splitCL100k,
+		special: map[string]int{
+			EndOfText: 100257, FIMPrefix: 100258, FIMMiddle: 100259, FIMSuffix: 100260, EndOfPrompt: 100276,
+		},
+	},
+	O200kBase: {
+		rankFile: O200kBase,
+		split:    splitO200k,
+		special:  map[string]int{EndOfText: 199999, EndOfPrompt: 200018},
+	},
+}
+
+// Encoding converts between text and tokens. An Encoding is safe for concurrent use.
+type Encoding struct {
+	name    Name
+	split   func(string) int
+	ranks   map[string]int
+	decoder map[int]string
+	special map[string]int
+}
+
+// New returns the Encoding named |name| defined by |ranks|, which maps each token's bytes to its rank (i.e. its
+// token ID). See LoadRanks.
+func New(name Name, ranks map[string]int) (*Encoding, error) {
+	var sp, ok = specs[name]
+	if !ok {
+		return nil, &UnknownEncodingError{Name: name}
+	}
+
+	var e = &Encoding{
+		name:    name,
+		split:   sp.split,
+		ranks:   ranks,
+		decoder: make(map[int]string, len(ranks)+len(sp.special)),
+		special: sp.special,
+	}
+	for k, v := range ranks {
+		e.decoder[v] = k
+	}
+	for k, v := range sp.special {
+		e.decoder[v] = k
+	}
+
+	return e, nil
+}
+
+// Name returns the name of the encoding.
+func (e *Encoding) Name() Name {
+	return e.name
+}
+
+// Encode returns the tokens for |text|. Special tokens (e.g. "<|endoftext|>") are encoded as ordinary text.
+func (e *Encoding) Encode(text string) []int {
+	var out []int
+	for len(text) > 0 {
+		var n = e.split(text)
+		out = e.encodePiece(out, text[:n])
+		text = text[n:]
+	}
+
+	return out
+}
+
+// EncodeWithSpecialTokens returns the tokens for |text|, encoding any of the encoding's special tokens which appear in
+// |text| as their special token IDs.
+func (e *Encoding) EncodeWithSpecialTokens(text string) []int {
+	var out []int
+	for len(text) > 0 {
+		var i, tok = e.nextSpecial(text)
+		if i < 0 {
+			return append(out, e.Encode(text)...)
+		}
+
+		out = append(out, e.Encode(text[:i])...)
+		out = append(out, e.special[tok])
+		text = text[i+len(tok):]
+	}
+
+	return out
+}
+
+// nextSpecial returns the index and value of the first special token in |text|, or -1 if there is none.
+func (e *Encoding) nextSpecial(text string) (int, string) {
+	var idx, tok = -1, ""
+	for s := range e.special {
+		if i := strings.Index(text, s); i >= 0 && (idx < 0 || i < idx || (i == idx && len(s) > len(tok))) {
+			idx, tok = i, s
+		}
+	}
+
+	return idx, tok
+}
+
+// Count returns the number of tokens in |text|, as encoded by Encode.
+func (e *Encoding) Count(text string) int {
+	var n int
+	for len(text) > 0 {
+		var i = e.split(text)
+		if _, ok := e.ranks[text[:i]]; ok {
+			n++
+		} else {
+			n += len(e.bytePairMerge(text[:i])) - 1
+		}
+		text = text[i:]
+	}
+
+	return n
+}
+
+// Decode returns the text for |tokens|. Unknown tokens are ignored. If |tokens| splits a multi-byte UTF-8 sequence,
+// the result will contain invalid UTF-8; use DecodeBytes to handle this explicitly.
+func (e *Encoding) Decode(tokens []int) string {
+	return string(e.DecodeBytes(tokens))
+}
+
+// DecodeBytes returns the bytes for |tokens|. Unknown tokens are ignored.
+func (e *Encoding) DecodeBytes(tokens []int) []byte {
+	var out []byte
+	for _, t := range tokens {
+		out = append(out, e.decoder[t]...)
+	}
+
+	return out
+}
+
+// DecodeValid is like Decode, but replaces any invalid UTF-8 with the Unicode replacement character.
+func (e *Encoding) DecodeValid(tokens []int) string {
+	return strings.ToValidUTF8(e.Decode(tokens), string(utf8.RuneError))
+}
+
+// TokenID returns the ID of the token whose bytes are exactly |s|, including special tokens.
+func (e *Encoding) TokenID(s string) (int, bool) {
+	if id, ok := e.special[s]; ok {
+		return id, true
+	}
+
+	var id, ok = e.ranks[s]
+
+	return id, ok
+}
+
+func (e *Encoding) encodePiece(out []int, piece string) []int {
+	if id, ok := e.ranks[piece]; ok {
+		return append(out, id)
+	}
+
+	var parts = e.bytePairMerge(piece)
+	for i := 0; i < len(parts)-1; i++ {
+		out = append(out, e.ranks[piece[parts[i]:parts[i+1]]])
+	}
+
+	return out
+}
+
+// bytePairMerge repeatedly merges the adjacent pair of parts of |piece| whose concatenation has the lowest rank, until
+// no adjacent pair is a known token. It returns the boundaries of the resulting parts, including 0 and len(piece).
+func (e *Encoding) bytePairMerge(piece string) []int {
+	var starts = make([]int, len(piece)+1)
+	for i := range starts {
+		starts[i] = i
+	}
+
+	// rank returns the rank of the merge of parts i and i+1.
+	var rank = func(i int) int {
+		if i+2 >= len(starts) {
+			return math.MaxInt
+		}
+		if r, ok := e.ranks[piece[starts[i]:starts[i+2]]]; ok {
+			return r
+		}
+		return math.MaxInt
+	}
+
+	var ranks = make([]int, len(starts))
+	for i := range ranks {
+		ranks[i] = rank(i)
+	}
+
+	for {
+		var min, at = math.MaxInt, -1
+		for i := 0; i < len(ranks)-1; i++ {
+			if ranks[i] < min {
+				min, at = ranks[i], i
+			}
+		}
+		if at < 0 {
+			break
+		}
+
+		starts = append(starts[:at+1], starts[at+2:]...)
+		ranks = append(ranks[:at+1], ranks[at+2:]...)
+		ranks[at] = rank(at)
+		if at > 0 {
+			ranks[at-1] = rank(at - 1)
+		}
+	}
+
+	return starts
+}
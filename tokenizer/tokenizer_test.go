@@ -0,0 +1,240 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/fabiustech/openai/models"
+)
+
+func split(s string, f func(string) int) []string {
+	var out []string
+	for len(s) > 0 {
+		var n = f(s)
+		out = append(out, s[:n])
+		s = s[n:]
+	}
+
+	return out
+}
+
+func TestSplit(t *testing.T) {
+	var tcs = []struct {
+		in                  string
+		gpt2, cl100k, o200k []string
+	}{
+		{
+			in:     "Hello world",
+			gpt2:   []string{"Hello", " world"},
+			cl100k: []string{"Hello", " world"},
+			o200k:  []string{"Hello", " world"},
+		},
+		{
+			in:     "I'm HERE'S 12345",
+			gpt2:   []string{"I", "'m", " HERE", "'", "S", " 12345"},
+			cl100k: []string{"I", "'m", " HERE", "'S", " ", "123", "45"},
+			o200k:  []string{"I'm", " HERE'S", " ", "123", "45"},
+		},
+		{
+			in:     "a  \n\n  b",
+			gpt2:   []string{"a", "  \n\n ", " b"},
+			cl100k: []string{"a", "  \n\n", " ", " b"},
+			o200k:  []string{"a", "  \n\n", " ", " b"},
+		},
+		{
+			in:     "CamelCase foo/bar\n",
+			gpt2:   []string{"CamelCase", " foo", "/", "bar", "\n"},
+			cl100k: []string{"CamelCase", " foo", "/bar", "\n"},
+			o200k:  []string{"Camel", "Case", " foo", "/bar", "\n"},
+		},
+		{
+			in:     "!!\r\n end",
+			gpt2:   []string{"!!", "\r\n", " end"},
+			cl100k: []string{"!!\r\n", " end"},
+			o200k:  []string{"!!\r\n", " end"},
+		},
+	}
+
+	for _, tc := range tcs {
+		if got := split(tc.in, splitGPT2); !reflect.DeepEqual(got, tc.gpt2) {
+			t.Errorf("gpt2 %q: expected %q, got %q", tc.in, tc.gpt2, got)
+		}
+		if got := split(tc.in, splitCL100k); !reflect.DeepEqual(got, tc.cl100k) {
+			t.Errorf("cl100k %q: expected %q, got %q", tc.in, tc.cl100k, got)
+		}
+		if got := split(tc.in, splitO200k); !reflect.DeepEqual(got, tc.o200k) {
+			t.Errorf("o200k %q: expected %q, got %q", tc.in, tc.o200k, got)
+		}
+	}
+}
+
+// testRanks returns a rank file containing every single byte, followed by a few merges.
+func testRanks() string {
+	var sb strings.Builder
+	var add = func(tok string, rank int) {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(tok)), rank)
+	}
+	for i := 0; i < 256; i++ {
+		add(string([]byte{byte(i)}), i)
+	}
+	for i, tok := range []string{"he", "ll", "hell", "hello", " w"} {
+		add(tok, 256+i)
+	}
+
+	return sb.String()
+}
+
+func TestEncoding(t *testing.T) {
+	var e, err = Load(CL100kBase, strings.NewReader(testRanks()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tcs = []struct {
+		in   string
+		want []int
+	}{
+		{in: "hello", want: []int{259}},
+		{in: "hello world", want: []int{259, 260, 'o', 'r', 'l', 'd'}},
+		{in: "shell", want: []int{'s', 258}},
+		{in: "héllo", want: []int{'h', 0xc3, 0xa9, 257, 'o'}},
+	}
+
+	for _, tc := range tcs {
+		var got = e.Encode(tc.in)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: expected %v, got %v", tc.in, tc.want, got)
+		}
+		if n := e.Count(tc.in); n != len(tc.want) {
+			t.Errorf("%q: expected count %d, got %d", tc.in, len(tc.want), n)
+		}
+		if s := e.Decode(got); s != tc.in {
+			t.Errorf("%q: decoded to %q", tc.in, s)
+		}
+	}
+
+	var text = "hello<|endoftext|>"
+	if got, want := e.EncodeWithSpecialTokens(text), []int{259, 100257}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := e.Encode(text); len(got) != 1+len(EndOfText) {
+		t.Errorf("expected special token to be encoded as text, got %v", got)
+	}
+	if s := e.Decode(e.EncodeWithSpecialTokens(text)); s != text {
+		t.Errorf("expected %q, got %q", text, s)
+	}
+
+	if s := e.DecodeValid([]int{0xc3}); s != "�" {
+		t.Errorf("expected replacement character, got %q", s)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	if _, err := Load("nope", strings.NewReader("")); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+	if _, err := Load(CL100kBase, strings.NewReader("aGk=\n")); err == nil {
+		t.Error("expected error for missing rank")
+	}
+	if _, err := Load(CL100kBase, strings.NewReader("!!! 1\n")); err == nil {
+		t.Error("expected error for bad base64")
+	}
+}
+
+func TestGet(t *testing.T) {
+	SetSource(fstest.MapFS{"p50k_base.tiktoken": {Data: []byte(testRanks())}})
+	t.Cleanup(func() { SetSource(nil) })
+
+	var e, err = Get(P50kEdit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Name() != P50kEdit {
+		t.Errorf("expected %s, got %s", P50kEdit, e.Name())
+	}
+	if id, ok := e.TokenID(FIMMiddle); !ok || id != 50282 {
+		t.Errorf("expected %s to be 50282, got %d", FIMMiddle, id)
+	}
+
+	var again *Encoding
+	if again, err = Get(P50kEdit); err != nil || again != e {
+		t.Errorf("expected cached encoding, got %p, %v", again, err)
+	}
+
+	if _, err = Get(CL100kBase); err == nil {
+		t.Error("expected error for missing rank file")
+	}
+}
+
+func TestEncodingName(t *testing.T) {
+	// Every enum value up to the first without a string representation must map to an encoding.
+	var check = func(m fmt.Stringer) {
+		if _, err := EncodingName(m); err != nil {
+			t.Error(err)
+		}
+	}
+	for m := models.ChatCompletion(1); m.String() != ""; m++ {
+		check(m)
+	}
+	for m := models.Completion(1); m.String() != ""; m++ {
+		check(m)
+	}
+	for m := models.Embedding(1); m.String() != ""; m++ {
+		check(m)
+	}
+	for m := models.Edit(1); m.String() != ""; m++ {
+		check(m)
+	}
+
+	var tcs = map[fmt.Stringer]Name{
+		models.GPT4:           CL100kBase,
+		models.GPT4oMini:      O200kBase,
+		models.O1Mini:         O200kBase,
+		models.TextDavinci003: P50kBase,
+		models.TextAda001:     R50kBase,
+		models.AdaEmbeddingV2: CL100kBase,
+		models.AdaSimilarity:  R50kBase,
+	}
+	for m, want := range tcs {
+		if got, _ := EncodingName(m); got != want {
+			t.Errorf("%s: expected %s, got %s", m, want, got)
+		}
+	}
+
+	if _, err := EncodingName(models.UnknownChatCompletion); err == nil {
+		t.Error("expected error for unknown model")
+	}
+}
+
+// TestRankFiles checks known encodings against the real rank files, if they are available in the directory named by
+// OPENAI_TOKENIZER_DIR.
+func TestRankFiles(t *testing.T) {
+	if os.Getenv(DirEnv) == "" {
+		t.Skipf("%s not set", DirEnv)
+	}
+
+	var tcs = []struct {
+		name Name
+		in   string
+		want []int
+	}{
+		{name: CL100kBase, in: "hello world", want: []int{15339, 1917}},
+		{name: O200kBase, in: "hello world", want: []int{24912, 2375}},
+		{name: R50kBase, in: "hello world", want: []int{31373, 995}},
+	}
+
+	for _, tc := range tcs {
+		var e, err = Get(tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := e.Encode(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s %q: expected %v, got %v", tc.name, tc.in, tc.want, got)
+		}
+	}
+}
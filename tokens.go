@@ -0,0 +1,257 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/tokenizer"
+)
+
+// CountChatTokens returns the number of prompt tokens a ChatCompletionRequest for |model| with |messages| and
+// |functions| will be billed for, i.e. the expected Usage.PromptTokens. It accounts for the tokens the API adds to
+// frame each message and to prime the reply, and for the definitions of |functions|, which the API renders into the
+// prompt. To count Tools, pass the Function of each.
+//
+// The encoding for |model| is loaded with tokenizer.ForModel, so its rank file must be available; see the tokenizer
+// package. The framing rules are not documented by OpenAI, so the count is an estimate, although it is exact for the
+// common cases. Only the text parts of ContentParts are counted.
+func CountChatTokens(model models.ChatCompletion, messages []*ChatMessage, functions []*Function) (int, error) {
+	var enc, err = tokenizer.ForModel(model)
+	if err != nil {
+		return 0, err
+	}
+
+	return countChatTokens(enc, model, messages, functions)
+}
+
+// functions returns the Functions of |r|, followed by the Function of each of its Tools.
+func (r *ChatCompletionRequest) functions() []*Function {
+	var functions = make([]*Function, 0, len(r.Functions)+len(r.Tools))
+	for _, f := range r.Functions {
+		if f != nil {
+			functions = append(functions, f)
+		}
+	}
+	for _, t := range r.Tools {
+		if t != nil && t.Function != nil {
+			functions = append(functions, t.Function)
+		}
+	}
+
+	return functions
+}
+
+func countChatTokens(enc *tokenizer.Encoding, model models.ChatCompletion, messages []*ChatMessage, functions []*Function) (int, error) {
+	// Every message is framed as <|start|>{role/name}\n{content}<|end|>\n. gpt-3.5-turbo-0301 frames names differently.
+	var perMessage, perName = 3, 1
+	if model == models.GPT3Dot5Turbo0301 {
+		perMessage, perName = 4, -1
+	}
+
+	var n int
+	var paddedSystem bool
+	for _, m := range messages {
+		if m == nil {
+			continue
+		}
+		var content = m.Text()
+		// When functions are present, the first system message is followed by a newline, before the definitions.
+		if len(functions) > 0 && m.Role == System && !paddedSystem {
+			content += "\n"
+			paddedSystem = true
+		}
+
+		n += perMessage + enc.Count(string(m.Role)) + enc.Count(content)
+		if m.Name != "" {
+			n += enc.Count(m.Name) + perName
+		}
+		if m.Role == RoleFunction {
+			n -= 2
+		}
+
+		var calls []*FunctionCallResponse
+		if m.FunctionCall != nil {
+			calls = append(calls, m.FunctionCall)
+		}
+		for _, tc := range m.ToolCalls {
+			if tc != nil && tc.Function != nil {
+				calls = append(calls, tc.Function)
+			}
+		}
+		for _, c := range calls {
+			n += enc.Count(c.Name) + enc.Count(c.Arguments) + 3
+		}
+	}
+
+	// Every reply is primed with <|start|>assistant<|message|>.
+	n += 3
+
+	if len(functions) > 0 {
+		var defs, err = functionDefinitions(functions)
+		if err != nil {
+			return 0, err
+		}
+		n += enc.Count(defs) + 9
+
+		if paddedSystem {
+			n -= 4
+		}
+	}
+
+	return n, nil
+}
+
+// functionDefinitions renders |functions| as the API does when adding them to the prompt: as TypeScript type
+// declarations.
+func functionDefinitions(functions []*Function) (string, error) {
+	var lines = []string{"namespace functions {", ""}
+	for _, f := range functions {
+		if f == nil {
+			continue
+		}
+		if f.Description != "" {
+			lines = append(lines, "// "+f.Description)
+		}
+
+		var params promptSchema
+		if len(bytes.TrimSpace(f.Parameters)) > 0 {
+			if err := json.Unmarshal(f.Parameters, &params); err != nil {
+				return "", fmt.Errorf("openai: invalid parameters for function %q: %w", f.Name, err)
+			}
+		}
+
+		if len(params.Properties) > 0 {
+			lines = append(lines, "type "+f.Name+" = (_: {", params.properties(0), "}) => any;")
+		} else {
+			lines = append(lines, "type "+f.Name+" = () => any;")
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, "} // namespace functions")
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// promptSchema is the subset of a JSON Schema used when rendering function definitions. Like schema.Schema, it
+// preserves the order of properties, which affects the rendered definitions; unlike it, it accepts any "type", as
+// Function.Parameters may be arbitrary JSON Schema.
+type promptSchema struct {
+	Type        any
+	Description string
+	Enum        []any
+	Properties  []*promptProperty
+	Required    []string
+	Items       *promptSchema
+}
+
+type promptProperty struct {
+	Name   string
+	Schema *promptSchema
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *promptSchema) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Type        any             `json:"type"`
+		Description string          `json:"description"`
+		Enum        []any           `json:"enum"`
+		Properties  json.RawMessage `json:"properties"`
+		Required    []string        `json:"required"`
+		Items       *promptSchema   `json:"items"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	*s = promptSchema{
+		Type:        aux.Type,
+		Description: aux.Description,
+		Enum:        aux.Enum,
+		Required:    aux.Required,
+		Items:       aux.Items,
+	}
+	if len(aux.Properties) == 0 || bytes.Equal(aux.Properties, []byte("null")) {
+		return nil
+	}
+
+	var dec = json.NewDecoder(bytes.NewReader(aux.Properties))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		var tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+
+		var p = &promptProperty{Name: fmt.Sprint(tok), Schema: new(promptSchema)}
+		if err = dec.Decode(p.Schema); err != nil {
+			return err
+		}
+		s.Properties = append(s.Properties, p)
+	}
+
+	return nil
+}
+
+func (s *promptSchema) properties(indent int) string {
+	var lines []string
+	for _, p := range s.Properties {
+		if p.Schema.Description != "" && indent < 2 {
+			lines = append(lines, "// "+p.Schema.Description)
+		}
+
+		var optional = "?"
+		for _, r := range s.Required {
+			if r == p.Name {
+				optional = ""
+				break
+			}
+		}
+		lines = append(lines, p.Name+optional+": "+p.Schema.typeName(indent)+",")
+	}
+
+	var pad = strings.Repeat(" ", indent)
+	for i := range lines {
+		lines[i] = pad + lines[i]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (s *promptSchema) typeName(indent int) string {
+	var t, _ = s.Type.(string)
+	switch t {
+	case "string", "number", "integer":
+		if len(s.Enum) == 0 {
+			if t == "string" {
+				return "string"
+			}
+			return "number"
+		}
+
+		var vals = make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			if t == "string" {
+				vals[i] = `"` + fmt.Sprint(v) + `"`
+			} else {
+				vals[i] = fmt.Sprint(v)
+			}
+		}
+		return strings.Join(vals, " | ")
+	case "boolean", "null":
+		return t
+	case "object":
+		return "{\n" + s.properties(indent+2) + "\n}"
+	case "array":
+		if s.Items == nil {
+			return "any[]"
+		}
+		return s.Items.typeName(indent) + "[]"
+	default:
+		return ""
+	}
+}
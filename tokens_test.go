@@ -0,0 +1,201 @@
+package openai
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/tokenizer"
+)
+
+// byteEncoding returns an encoding in which every byte is a single token, so that token counts equal byte lengths.
+func byteEncoding(t *testing.T) *tokenizer.Encoding {
+	var ranks = make(map[string]int, 256)
+	for i := 0; i < 256; i++ {
+		ranks[string([]byte{byte(i)})] = i
+	}
+
+	var enc, err = tokenizer.New(tokenizer.CL100kBase, ranks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return enc
+}
+
+func TestCountChatTokensFraming(t *testing.T) {
+	var enc = byteEncoding(t)
+
+	var fn = &Function{
+		Name:        "get_weather",
+		Description: "Get the weather",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+	}
+	var defs, err = functionDefinitions([]*Function{fn})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tcs = []struct {
+		name      string
+		model     models.ChatCompletion
+		messages  []*ChatMessage
+		functions []*Function
+		want      int
+	}{
+		{
+			name:     "single message",
+			model:    models.GPT4,
+			messages: []*ChatMessage{{Role: User, Content: "hello"}},
+			want:     3 + len("user") + len("hello") + 3,
+		},
+		{
+			name:     "name",
+			model:    models.GPT4,
+			messages: []*ChatMessage{{Role: System, Name: "bob", Content: "hi"}},
+			want:     3 + len("system") + len("hi") + len("bob") + 1 + 3,
+		},
+		{
+			name:     "name 0301",
+			model:    models.GPT3Dot5Turbo0301,
+			messages: []*ChatMessage{{Role: System, Name: "bob", Content: "hi"}},
+			want:     4 + len("system") + len("hi") + len("bob") - 1 + 3,
+		},
+		{
+			name:  "content parts",
+			model: models.GPT4o,
+			messages: []*ChatMessage{{Role: User, ContentParts: []*ContentPart{
+				TextPart("look"), ImageURLPart("https://example.com/a.png", ImageDetailLow),
+			}}},
+			want: 3 + len("user") + len("look") + 3,
+		},
+		{
+			name:  "null content parts and tool calls",
+			model: models.GPT4o,
+			messages: []*ChatMessage{
+				{Role: User, ContentParts: []*ContentPart{nil, TextPart("look")}},
+				{Role: Assistant, ToolCalls: []*ToolCall{nil, {Function: &FunctionCallResponse{Name: "f", Arguments: "{}"}}}},
+			},
+			want: (3 + len("user") + len("look")) + (3 + len("assistant") + len("f") + len("{}") + 3) + 3,
+		},
+		{
+			name:  "function call",
+			model: models.GPT4,
+			messages: []*ChatMessage{
+				{Role: Assistant, FunctionCall: &FunctionCallResponse{Name: "f", Arguments: "{}"}},
+				{Role: RoleFunction, Name: "f", Content: "1"},
+			},
+			want: (3 + len("assistant") + len("f") + len("{}") + 3) + (3 + len("function") + len("1") + len("f") + 1 - 2) + 3,
+		},
+		{
+			name:      "functions",
+			model:     models.GPT4,
+			messages:  []*ChatMessage{{Role: User, Content: "hello"}},
+			functions: []*Function{fn},
+			want:      3 + len("user") + len("hello") + 3 + len(defs) + 9,
+		},
+		{
+			name:      "functions with system message",
+			model:     models.GPT4,
+			messages:  []*ChatMessage{{Role: System, Content: "be nice"}, {Role: User, Content: "hello"}},
+			functions: []*Function{fn},
+			want:      (3 + len("system") + len("be nice\n")) + (3 + len("user") + len("hello")) + 3 + len(defs) + 9 - 4,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			var got, err = countChatTokens(enc, tc.model, tc.messages, tc.functions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %d tokens, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestFunctionDefinitions(t *testing.T) {
+	var got, err = functionDefinitions([]*Function{
+		{
+			Name:        "get_weather",
+			Description: "Get the current weather.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{` +
+				`"location":{"type":"string","description":"The city."},` +
+				`"unit":{"type":"string","enum":["celsius","fahrenheit"]},` +
+				`"days":{"type":"array","items":{"type":"integer"}},` +
+				`"options":{"type":"object","properties":{"hourly":{"type":"boolean","description":"Nested descriptions are omitted."}},"required":["hourly"]}},` +
+				`"required":["location"]}`),
+		},
+		{
+			Name:       "now",
+			Parameters: json.RawMessage(`{"type":"object","properties":{}}`),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want = `namespace functions {
+
+// Get the current weather.
+type get_weather = (_: {
+// The city.
+location: string,
+unit?: "celsius" | "fahrenheit",
+days?: number[],
+options?: {
+  hourly: boolean,
+},
+}) => any;
+
+type now = () => any;
+
+} // namespace functions`
+	if got != want {
+		t.Fatalf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// TestCountChatTokens checks counts reported by the API, using the real rank files if they are available in the
+// directory named by OPENAI_TOKENIZER_DIR.
+func TestCountChatTokens(t *testing.T) {
+	if os.Getenv(tokenizer.DirEnv) == "" {
+		t.Skipf("%s not set", tokenizer.DirEnv)
+	}
+
+	// From https://cookbook.openai.com/examples/how_to_count_tokens_with_tiktoken.
+	var messages = []*ChatMessage{
+		{Role: System, Content: "You are a helpful, pattern-following assistant that translates corporate jargon into plain English."},
+		{Role: System, Name: "example_user", Content: "New synergies will help drive top-line growth."},
+		{Role: System, Name: "example_assistant", Content: "Things working well together will increase revenue."},
+		{Role: System, Name: "example_user", Content: "Let's circle back when we have more bandwidth to touch base on opportunities for increased leverage."},
+		{Role: System, Name: "example_assistant", Content: "Let's talk later when we're less busy about how to do better."},
+		{Role: User, Content: "This late pivot means we don't have time to boil the ocean for the client deliverable."},
+	}
+
+	var tcs = []struct {
+		model    models.ChatCompletion
+		messages []*ChatMessage
+		want     int
+	}{
+		{model: models.GPT3Dot5Turbo0301, messages: messages, want: 127},
+		{model: models.GPT3Dot5Turbo0613, messages: messages, want: 129},
+		{model: models.GPT4, messages: messages, want: 129},
+		{model: models.GPT4o, messages: messages, want: 124},
+		{model: models.GPT4, messages: []*ChatMessage{{Role: User, Content: "hello"}}, want: 8},
+		{model: models.GPT4o, messages: []*ChatMessage{{Role: User, Content: "hello world"}}, want: 9},
+	}
+
+	for _, tc := range tcs {
+		var got, err = CountChatTokens(tc.model, tc.messages, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: expected %d tokens, got %d", tc.model, tc.want, got)
+		}
+	}
+}
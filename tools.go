@@ -0,0 +1,318 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrMaxIterations is returned by ToolRunner.Run if the model is still calling tools after MaxIterations requests.
+var ErrMaxIterations = errors.New("openai: tool runner exceeded maximum iterations")
+
+// ErrUnknownTool is reported (via ToolCallResult.Err) when the model calls a tool which isn't registered.
+var ErrUnknownTool = errors.New("openai: unknown tool")
+
+// ToolRegistry is a set of Go functions which may be called by the model. Register functions with RegisterTool.
+// A ToolRegistry is safe for concurrent use.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]*registeredTool
+	order []string
+}
+
+type registeredTool struct {
+	fn   *Function
+	call func(ctx context.Context, args string) (string, error)
+}
+
+// NewToolRegistry returns an empty *ToolRegistry.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]*registeredTool)}
+}
+
+// RegisterTool registers |fn| with |r| under |name|. When the model calls the tool, its JSON encoded arguments are
+// decoded into an A and passed to |fn|. The returned R is sent back to the model: strings are sent verbatim, while
+// all other values are encoded as JSON. |parameters| is the JSON Schema describing A; if nil, it is generated from A
+// by the schema package. |fn| should return once its context is done; see ToolRunner.ToolTimeout.
+// RegisterTool returns an error if a tool named |name| has already been registered.
+func RegisterTool[A, R any](r *ToolRegistry, name, description string, parameters json.RawMessage, fn func(ctx context.Context, args A) (R, error)) error {
+	var f = &Function{
+		Name:        name,
+		Description: description,
+		Parameters:  parameters,
+	}
+	if parameters == nil {
+		var err error
+		if f, err = NewFunction[A](name, description); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tools[name]; ok {
+		return fmt.Errorf("openai: tool %q already registered", name)
+	}
+
+	r.tools[name] = &registeredTool{
+		fn: f,
+		call: func(ctx context.Context, args string) (string, error) {
+			var a A
+			if args != "" {
+				if err := json.Unmarshal([]byte(args), &a); err != nil {
+					return "", fmt.Errorf("openai: invalid arguments for tool %q: %w", name, err)
+				}
+			}
+
+			var res, err = fn(ctx, a)
+			if err != nil {
+				return "", err
+			}
+
+			if s, ok := any(res).(string); ok {
+				return s, nil
+			}
+
+			var b []byte
+			b, err = json.Marshal(res)
+
+			return string(b), err
+		},
+	}
+	r.order = append(r.order, name)
+
+	return nil
+}
+
+// Tools returns the registered functions as *Tools, in registration order, for use in ChatCompletionRequest.Tools.
+func (r *ToolRegistry) Tools() []*Tool {
+	var out []*Tool
+	for _, f := range r.Functions() {
+		out = append(out, NewFunctionTool(f))
+	}
+
+	return out
+}
+
+// Functions returns the registered functions, in registration order, for use in the deprecated
+// ChatCompletionRequest.Functions.
+func (r *ToolRegistry) Functions() []*Function {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out = make([]*Function, 0, len(r.order))
+	for _, name := range r.order {
+		out = append(out, r.tools[name].fn)
+	}
+
+	return out
+}
+
+// Call calls the tool named |name| with the JSON encoded |args|, and returns its encoded result.
+func (r *ToolRegistry) Call(ctx context.Context, name, args string) (string, error) {
+	r.mu.RLock()
+	var t, ok = r.tools[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownTool, name)
+	}
+
+	return t.call(ctx, args)
+}
+
+// ToolRunner drives a chat completion to completion, calling the tools in Registry whenever the model requests them
+// and sending their results back to the model.
+type ToolRunner struct {
+	// Client is the client used to create chat completions.
+	Client *Client
+	// Registry contains the tools which may be called.
+	Registry *ToolRegistry
+	// MaxIterations is the maximum number of chat completion requests made by a single call to Run.
+	// Defaults to 10.
+	MaxIterations int
+	// ToolTimeout limits the duration of each individual tool call. A zero value means no limit. Tools must return
+	// promptly once their context is done: a tool which ignores it is abandoned when ToolTimeout fires, but its
+	// goroutine keeps running (and leaks) until the tool returns.
+	ToolTimeout time.Duration
+}
+
+// ToolRunResult is the transcript of a call to ToolRunner.Run.
+type ToolRunResult struct {
+	// Response is the final response from the model, which contains no tool calls. Nil if Run returned an error.
+	Response *ChatCompletionResponse
+	// Messages is the full conversation: the request's Messages followed by every message generated by the model and
+	// every tool result.
+	Messages []*ChatMessage
+	// Steps contains an entry for each chat completion request made, in order.
+	Steps []*ToolRunStep
+}
+
+// ToolRunStep represents a single chat completion request made by ToolRunner.Run, and the tool calls it resulted in.
+type ToolRunStep struct {
+	// Response is the response from the model.
+	Response *ChatCompletionResponse
+	// Calls are the results of the tool calls requested in Response, in the order requested.
+	Calls []*ToolCallResult
+}
+
+// ToolCallResult is the result of a single tool call.
+type ToolCallResult struct {
+	// Call is the tool call requested by the model. For the deprecated function calling API, Call.ID is empty.
+	Call *ToolCall
+	// Output is the content sent back to the model. If Err is non-nil, it describes the error.
+	Output string
+	// Err is the error returned by the tool, if any, including a panic recovered from it.
+	Err error
+	// Duration is how long the tool call took.
+	Duration time.Duration
+}
+
+const defaultMaxIterations = 10
+
+// Run sends |cr| to the model and executes any tools it calls, concurrently if the model requests several at once,
+// sending the results back until the model responds without calling a tool. If neither |cr|.Tools nor
+// |cr|.Functions is set, the tools in Registry are used. Errors returned by tools are sent to the model rather than
+// aborting the run. |cr| is not modified.
+//
+// The returned *ToolRunResult is non-nil even if an error is returned, and contains the transcript up until the
+// error occurred. ErrMaxIterations is returned if the model is still calling tools after MaxIterations requests.
+func (r *ToolRunner) Run(ctx context.Context, cr *ChatCompletionRequest) (*ToolRunResult, error) {
+	var req = *cr
+	req.Messages = append([]*ChatMessage(nil), cr.Messages...)
+	if len(req.Tools) == 0 && len(req.Functions) == 0 {
+		req.Tools = r.Registry.Tools()
+	}
+
+	var max = r.MaxIterations
+	if max <= 0 {
+		max = defaultMaxIterations
+	}
+
+	var res = &ToolRunResult{}
+	for i := 0; i < max; i++ {
+		var resp, err = r.Client.CreateChatCompletion(ctx, &req)
+		if err != nil {
+			res.Messages = req.Messages
+			return res, err
+		}
+
+		var step = &ToolRunStep{Response: resp}
+		res.Steps = append(res.Steps, step)
+
+		if len(resp.Choices) == 0 || resp.Choices[0].Message == nil {
+			res.Messages = req.Messages
+			return res, errors.New("openai: chat completion response contained no message")
+		}
+
+		var msg = resp.Choices[0].Message
+		// Null tool calls in a malformed response cannot be answered, so they are dropped.
+		var calls = msg.ToolCalls[:0:0]
+		for _, tc := range msg.ToolCalls {
+			if tc != nil {
+				calls = append(calls, tc)
+			}
+		}
+		msg.ToolCalls = calls
+		req.Messages = append(req.Messages, msg)
+
+		switch {
+		case len(msg.ToolCalls) > 0:
+			step.Calls = r.callAll(ctx, msg.ToolCalls)
+			for _, c := range step.Calls {
+				req.Messages = append(req.Messages, &ChatMessage{
+					Role:       RoleTool,
+					Content:    c.Output,
+					ToolCallID: c.Call.ID,
+				})
+			}
+		case msg.FunctionCall != nil:
+			var c = r.call(ctx, &ToolCall{Type: ToolTypeFunction, Function: msg.FunctionCall})
+			step.Calls = []*ToolCallResult{c}
+			req.Messages = append(req.Messages, &ChatMessage{
+				Role:    RoleFunction,
+				Name:    msg.FunctionCall.Name,
+				Content: c.Output,
+			})
+		default:
+			res.Response = resp
+			res.Messages = req.Messages
+			return res, nil
+		}
+	}
+
+	res.Messages = req.Messages
+
+	return res, ErrMaxIterations
+}
+
+// callAll executes |calls| concurrently, and returns their results in the same order.
+func (r *ToolRunner) callAll(ctx context.Context, calls []*ToolCall) []*ToolCallResult {
+	var out = make([]*ToolCallResult, len(calls))
+
+	var wg sync.WaitGroup
+	for i, tc := range calls {
+		wg.Add(1)
+		go func(i int, tc *ToolCall) {
+			defer wg.Done()
+			out[i] = r.call(ctx, tc)
+		}(i, tc)
+	}
+	wg.Wait()
+
+	return out
+}
+
+func (r *ToolRunner) call(ctx context.Context, tc *ToolCall) *ToolCallResult {
+	var res = &ToolCallResult{Call: tc}
+	if tc.Function == nil {
+		res.Err = fmt.Errorf("%w: unsupported tool type %q", ErrUnknownTool, tc.Type)
+		res.Output = "error: " + res.Err.Error()
+		return res
+	}
+
+	if r.ToolTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.ToolTimeout)
+		defer cancel()
+	}
+
+	type result struct {
+		out string
+		err error
+	}
+
+	// The call is made in a separate goroutine so that tools which ignore |ctx| cannot exceed ToolTimeout. Such tools
+	// are abandoned, not stopped: their goroutine runs until they return.
+	var done = make(chan result, 1)
+	var start = time.Now()
+	go func() {
+		// A panicking tool fails its call, rather than the process.
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{err: fmt.Errorf("openai: tool %q panicked: %v", tc.Function.Name, p)}
+			}
+		}()
+
+		var out, err = r.Registry.Call(ctx, tc.Function.Name, tc.Function.Arguments)
+		done <- result{out: out, err: err}
+	}()
+
+	select {
+	case rs := <-done:
+		res.Output, res.Err = rs.out, rs.err
+	case <-ctx.Done():
+		res.Err = ctx.Err()
+	}
+	res.Duration = time.Since(start)
+
+	if res.Err != nil {
+		res.Output = "error: " + res.Err.Error()
+	}
+
+	return res
+}
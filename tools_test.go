@@ -0,0 +1,136 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+)
+
+type weatherArgs struct {
+	City string `json:"city"`
+}
+
+type weatherResult struct {
+	TempC int `json:"temp_c"`
+}
+
+func TestToolRunner(t *testing.T) {
+	var requests []*ChatCompletionRequest
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req = &ChatCompletionRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			http.Error(w, "could not read request", http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+
+		var msg = &ChatMessage{Role: Assistant, Content: "It is 21C in Paris."}
+		if len(requests) == 1 {
+			msg = &ChatMessage{Role: Assistant, ToolCalls: []*ToolCall{
+				{ID: "call_a", Type: ToolTypeFunction, Function: &FunctionCallResponse{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+				{ID: "call_b", Type: ToolTypeFunction, Function: &FunctionCallResponse{Name: "fail", Arguments: `{}`}},
+				{ID: "call_c", Type: ToolTypeFunction, Function: &FunctionCallResponse{Name: "missing", Arguments: `{}`}},
+				{ID: "call_d", Type: ToolTypeFunction, Function: &FunctionCallResponse{Name: "slow", Arguments: `{}`}},
+				nil,
+				{ID: "call_e", Type: ToolTypeFunction, Function: &FunctionCallResponse{Name: "panic", Arguments: `{}`}},
+			}}
+		}
+
+		var b, _ = json.Marshal(&ChatCompletionResponse{
+			Choices: []*ChatCompletionChoice{{Message: msg}},
+		})
+		_, _ = w.Write(b)
+	}))
+	defer ts.Close()
+
+	var registry = NewToolRegistry()
+	var err = RegisterTool(registry, "get_weather", "Gets the weather.", json.RawMessage(`{"type":"object"}`),
+		func(ctx context.Context, args weatherArgs) (*weatherResult, error) {
+			if args.City != "Paris" {
+				t.Errorf("unexpected city %q", args.City)
+			}
+			return &weatherResult{TempC: 21}, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = RegisterTool(registry, "fail", "Fails.", nil, func(ctx context.Context, args struct{}) (string, error) {
+		return "", errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = RegisterTool(registry, "slow", "Returns once cancelled.", nil, func(ctx context.Context, args struct{}) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = RegisterTool(registry, "panic", "Panics.", nil, func(ctx context.Context, args struct{}) (string, error) {
+		panic("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err = RegisterTool(registry, "fail", "", nil, func(ctx context.Context, args struct{}) (string, error) {
+		return "", nil
+	}); err == nil {
+		t.Fatal("expected error registering duplicate tool")
+	}
+
+	var client, _ = newTestClient(ts.URL)
+	var runner = &ToolRunner{Client: client, Registry: registry, ToolTimeout: 10 * time.Millisecond}
+
+	var res *ToolRunResult
+	res, err = runner.Run(context.Background(), &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []*ChatMessage{{Role: User, Content: "What's the weather in Paris?"}},
+	})
+	if err != nil {
+		t.Fatalf("Run error: %v", err)
+	}
+
+	if len(requests) != 2 || len(requests[0].Tools) != 4 {
+		t.Fatalf("unexpected requests %+v", requests)
+	}
+	if got := res.Response.Choices[0].Message.Content; got != "It is 21C in Paris." {
+		t.Errorf("unexpected final response %q", got)
+	}
+	if len(res.Steps) != 2 || len(res.Steps[0].Calls) != 5 {
+		t.Fatalf("unexpected steps %+v", res.Steps)
+	}
+
+	var calls = res.Steps[0].Calls
+	if calls[0].Output != `{"temp_c":21}` || calls[0].Err != nil {
+		t.Errorf("unexpected result %+v", calls[0])
+	}
+	if calls[1].Err == nil || calls[1].Output != "error: boom" {
+		t.Errorf("unexpected result %+v", calls[1])
+	}
+	if !errors.Is(calls[2].Err, ErrUnknownTool) {
+		t.Errorf("unexpected result %+v", calls[2])
+	}
+	if !errors.Is(calls[3].Err, context.DeadlineExceeded) {
+		t.Errorf("unexpected result %+v", calls[3])
+	}
+	if calls[4].Err == nil || calls[4].Output != `error: openai: tool "panic" panicked: boom` {
+		t.Errorf("unexpected result %+v", calls[4])
+	}
+
+	// user, assistant, 5 * tool, assistant.
+	if len(res.Messages) != 8 {
+		t.Fatalf("expected 8 messages, got %d", len(res.Messages))
+	}
+	if n := len(requests[1].Messages[1].ToolCalls); n != 5 {
+		t.Errorf("expected the null tool call to be dropped, got %d tool calls", n)
+	}
+	for i, id := range []string{"call_a", "call_b", "call_c", "call_d", "call_e"} {
+		if m := requests[1].Messages[i+2]; m.Role != RoleTool || m.ToolCallID != id {
+			t.Errorf("unexpected tool message %+v", m)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/routes"
+)
+
+// Tracer starts Spans. It is an adapter interface, so that any tracing library can be used without this module
+// depending on it. For example, an OpenTelemetry trace.Tracer can be adapted with:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, openai.Span) {
+//		var span trace.Span
+//		ctx, span = t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{span}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttributes(attrs ...openai.Attribute) {
+//		s.Span.SetAttributes(otelAttributes(attrs)...)
+//	}
+//
+//	func (s otelSpan) AddEvent(name string, attrs ...openai.Attribute) {
+//		s.Span.AddEvent(name, trace.WithAttributes(otelAttributes(attrs)...))
+//	}
+//
+//	func (s otelSpan) RecordError(err error) {
+//		s.Span.RecordError(err)
+//		s.Span.SetStatus(codes.Error, err.Error())
+//	}
+//
+//	func otelAttributes(attrs []openai.Attribute) []attribute.KeyValue {
+//		var kvs = make([]attribute.KeyValue, 0, len(attrs))
+//		for _, a := range attrs {
+//			switch v := a.Value.(type) {
+//			case string:
+//				kvs = append(kvs, attribute.String(a.Key, v))
+//			case int:
+//				kvs = append(kvs, attribute.Int(a.Key, v))
+//			case float64:
+//				kvs = append(kvs, attribute.Float64(a.Key, v))
+//			case bool:
+//				kvs = append(kvs, attribute.Bool(a.Key, v))
+//			case []string:
+//				kvs = append(kvs, attribute.StringSlice(a.Key, v))
+//			}
+//		}
+//		return kvs
+//	}
+type Tracer interface {
+	// Start starts a Span named |name|, as a child of any Span in |ctx|, and returns a context containing it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation. See Tracer.
+type Span interface {
+	// SetAttributes sets |attrs| on the Span.
+	SetAttributes(attrs ...Attribute)
+	// AddEvent records an event named |name| with |attrs|.
+	AddEvent(name string, attrs ...Attribute)
+	// RecordError records |err| and marks the Span as failed.
+	RecordError(err error)
+	// End completes the Span.
+	End()
+}
+
+// Attribute is a key-value pair describing a Span. Value is a string, int, float64, bool or []string.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attribute keys set on Spans, following the OpenTelemetry semantic conventions for generative AI systems:
+// https://opentelemetry.io/docs/specs/semconv/gen-ai/gen-ai-spans/.
+const (
+	AttrSystem               = "gen_ai.system"
+	AttrOperationName        = "gen_ai.operation.name"
+	AttrRequestModel         = "gen_ai.request.model"
+	AttrRequestMaxTokens     = "gen_ai.request.max_tokens"
+	AttrRequestTemperature   = "gen_ai.request.temperature"
+	AttrRequestTopP          = "gen_ai.request.top_p"
+	AttrRequestStopSequences = "gen_ai.request.stop_sequences"
+	AttrResponseID           = "gen_ai.response.id"
+	AttrResponseModel        = "gen_ai.response.model"
+	AttrResponseFinishReason = "gen_ai.response.finish_reasons"
+	AttrUsageInputTokens     = "gen_ai.usage.input_tokens"
+	AttrUsageOutputTokens    = "gen_ai.usage.output_tokens"
+	AttrServerAddress        = "server.address"
+	AttrServerPort           = "server.port"
+	AttrErrorType            = "error.type"
+	AttrRequestID            = "openai.request.id"
+)
+
+// EventFirstToken is the name of the Span event recorded when the first event of a stream is received.
+const EventFirstToken = "gen_ai.first_token"
+
+// AttrTimeToFirstToken is the attribute of the EventFirstToken event which holds the time, in seconds, from the start
+// of the request to the first event of the stream. The semantic conventions define gen_ai.server.time_to_first_token
+// as a metric rather than a Span attribute; see CallMetrics.TimeToFirstEvent to record it as one.
+const AttrTimeToFirstToken = "openai.time_to_first_token"
+
+// WithTracer configures the Client to trace every API call with |t|. Each Span is named after the operation and
+// model (e.g. "chat gpt-4o"), and carries the request parameters, response ID, finish reasons and token usage. For
+// streaming calls, the Span ends with the stream, and records the first event as an EventFirstToken event. See
+// TracingMiddleware.
+func WithTracer(t Tracer) Option {
+	return WithMiddleware(TracingMiddleware(t))
+}
+
+// TracingMiddleware returns Middleware which traces every API call with |t|. See WithTracer.
+func TracingMiddleware(t Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) (*OperationResult, error) {
+			var name = operationName(op.Route)
+			var model = op.Model()
+
+			var spanName = name
+			if model != "" {
+				spanName += " " + model
+			}
+
+			var span Span
+			ctx, span = t.Start(ctx, spanName)
+			span.SetAttributes(requestAttributes(op, name, model)...)
+
+			var start = time.Now()
+			var res, err = next(ctx, op)
+			if err != nil {
+				endSpan(span, err)
+				return nil, err
+			}
+			if res == nil || (op.Stream && res.Response == nil) {
+				// The Client fails the call with errNoResult.
+				endSpan(span, errNoResult)
+				return res, nil
+			}
+
+			if res.Response != nil && res.Response.Request != nil {
+				span.SetAttributes(serverAttributes(res.Response.Request.URL)...)
+			}
+			if res.Response != nil {
+				if id := res.Response.Header.Get(headerRequestID); id != "" {
+					span.SetAttributes(Attribute{AttrRequestID, id})
+				}
+			}
+
+			if !op.Stream {
+				var r = &tracedResponse{}
+				_ = json.Unmarshal(res.Body, r)
+				span.SetAttributes(r.attributes()...)
+				span.End()
+				return res, nil
+			}
+
+			var r = &tracedResponse{}
+			var first = true
+			var prev = res.OnEvent
+			res.OnEvent = func(event any, err error) {
+				if event != nil {
+					if first {
+						span.AddEvent(EventFirstToken, Attribute{AttrTimeToFirstToken, time.Since(start).Seconds()})
+						first = false
+					}
+					r.add(event)
+				} else {
+					span.SetAttributes(r.attributes()...)
+					endSpan(span, err)
+				}
+				if prev != nil {
+					prev(event, err)
+				}
+			}
+
+			return res, nil
+		}
+	}
+}
+
+// endSpan records |err|, if any, and ends |span|.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.SetAttributes(Attribute{AttrErrorType, errorType(err)})
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// errorType classifies |err| with a low-cardinality value: the Type of an *Error (or its status code if it has none),
+// "timeout" if the context was cancelled or timed out, and "_OTHER" otherwise.
+func errorType(err error) string {
+	var apiErr *Error
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Type != "":
+		return apiErr.Type
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return "timeout"
+	default:
+		return "_OTHER"
+	}
+}
+
+// operationName returns the GenAI operation name of |route|. Routes without one are named after the route.
+func operationName(route string) string {
+	switch route {
+	case routes.ChatCompletions:
+		return "chat"
+	case routes.Completions:
+		return "text_completion"
+	case routes.Embeddings:
+		return "embeddings"
+	default:
+		return route
+	}
+}
+
+func requestAttributes(op *Operation, name, model string) []Attribute {
+	var attrs = []Attribute{{AttrSystem, "openai"}, {AttrOperationName, name}}
+	if model != "" {
+		attrs = append(attrs, Attribute{AttrRequestModel, model})
+	}
+
+	var maxTokens int
+	var temperature, topP *float64
+	var stop []string
+	switch r := op.Request.(type) {
+	case *ChatCompletionRequest:
+		maxTokens, temperature, topP, stop = r.MaxTokens, r.Temperature, r.TopP, r.Stop
+	case *CompletionRequest[models.Completion]:
+		maxTokens, temperature, topP, stop = r.MaxTokens, r.Temperature, r.TopP, r.Stop
+	case *CompletionRequest[models.FineTunedModel]:
+		maxTokens, temperature, topP, stop = r.MaxTokens, r.Temperature, r.TopP, r.Stop
+	}
+
+	if maxTokens > 0 {
+		attrs = append(attrs, Attribute{AttrRequestMaxTokens, maxTokens})
+	}
+	if temperature != nil {
+		attrs = append(attrs, Attribute{AttrRequestTemperature, *temperature})
+	}
+	if topP != nil {
+		attrs = append(attrs, Attribute{AttrRequestTopP, *topP})
+	}
+	if len(stop) > 0 {
+		attrs = append(attrs, Attribute{AttrRequestStopSequences, stop})
+	}
+
+	return attrs
+}
+
+func serverAttributes(u *url.URL) []Attribute {
+	var attrs = []Attribute{{AttrServerAddress, u.Hostname()}}
+
+	var port = u.Port()
+	if port == "" && u.Scheme == "https" {
+		port = "443"
+	} else if port == "" && u.Scheme == "http" {
+		port = "80"
+	}
+	if p, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, Attribute{AttrServerPort, p})
+	}
+
+	return attrs
+}
+
+// tracedResponse holds the fields of a response, or of the events of a stream, which are traced.
+type tracedResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []*struct {
+		Index        int     `json:"index"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+
+	finishReasons map[int]string
+}
+
+// add merges the streamed |event| into the response.
+func (r *tracedResponse) add(event any) {
+	switch e := event.(type) {
+	case *ChatCompletionChunk:
+		r.ID = e.ID
+		for _, c := range e.Choices {
+			r.finish(c.Index, c.FinishReason)
+		}
+		if e.Usage != nil {
+			r.Usage = e.Usage
+		}
+	case *CompletionResponse[models.Completion]:
+		r.ID, r.Model = e.ID, e.Model.String()
+		for _, c := range e.Choices {
+			r.finish(c.Index, c.FinishReason)
+		}
+		if e.Usage != nil {
+			r.Usage = e.Usage
+		}
+	}
+}
+
+// finish records the |reason| the choice at |index| finished, if set.
+func (r *tracedResponse) finish(index int, reason *string) {
+	if reason == nil || *reason == "" {
+		return
+	}
+	if r.finishReasons == nil {
+		r.finishReasons = make(map[int]string)
+	}
+	r.finishReasons[index] = *reason
+}
+
+func (r *tracedResponse) attributes() []Attribute {
+	var attrs []Attribute
+	if r.ID != "" {
+		attrs = append(attrs, Attribute{AttrResponseID, r.ID})
+	}
+	if r.Model != "" {
+		attrs = append(attrs, Attribute{AttrResponseModel, r.Model})
+	}
+
+	for _, c := range r.Choices {
+		r.finish(c.Index, c.FinishReason)
+	}
+
+	var indices = make([]int, 0, len(r.finishReasons))
+	for i := range r.finishReasons {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	var reasons = make([]string, 0, len(indices))
+	for _, i := range indices {
+		reasons = append(reasons, r.finishReasons[i])
+	}
+	if len(reasons) > 0 {
+		attrs = append(attrs, Attribute{AttrResponseFinishReason, reasons})
+	}
+
+	if r.Usage != nil {
+		attrs = append(attrs,
+			Attribute{AttrUsageInputTokens, r.Usage.PromptTokens},
+			Attribute{AttrUsageOutputTokens, r.Usage.CompletionTokens},
+		)
+	}
+
+	return attrs
+}
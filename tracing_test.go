@@ -0,0 +1,173 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/params"
+)
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var s = &testSpan{name: name, attrs: make(map[string]any)}
+	t.spans = append(t.spans, s)
+
+	return ctx, s
+}
+
+type testSpan struct {
+	name       string
+	attrs      map[string]any
+	events     []string
+	eventAttrs map[string]any
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttributes(attrs ...Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) AddEvent(name string, attrs ...Attribute) {
+	s.events = append(s.events, name)
+	if s.eventAttrs == nil {
+		s.eventAttrs = make(map[string]any)
+	}
+	for _, a := range attrs {
+		s.eventAttrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) RecordError(err error) { s.err = err }
+func (s *testSpan) End()                  { s.ended = true }
+
+func TestTracer(t *testing.T) {
+	var ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "req_123")
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			if r.Header.Get("Accept") == "text/event-stream; charset=utf-8" {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = fmt.Fprint(w, `data: {"id":"2","choices":[{"index":1,"delta":{"content":"a"},"finish_reason":"length"}]}`+"\n\n")
+				_, _ = fmt.Fprint(w, `data: {"id":"2","choices":[{"index":0,"delta":{"content":"b"},"finish_reason":"stop"}]}`+"\n\n")
+				_, _ = fmt.Fprint(w, `data: {"id":"2","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`+"\n\n")
+				_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"1","model":"gpt-4o-2024-08-06","choices":[{"index":0,"finish_reason":"stop"}],` +
+				`"usage":{"prompt_tokens":8,"completion_tokens":2,"total_tokens":10}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad","type":"invalid_request_error"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	var tracer = &testTracer{}
+	var client = NewClient(testToken, WithBaseURL(ts.URL+"/v1"), WithTracer(tracer))
+	var req = &ChatCompletionRequest{
+		Model:       models.GPT4o,
+		Messages:    []*ChatMessage{{Role: User, Content: "hi"}},
+		MaxTokens:   100,
+		Temperature: params.Optional(0.5),
+	}
+
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion error: %v", err)
+	}
+
+	var chunks, errs, err = client.CreateStreamingChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateStreamingChatCompletion error: %v", err)
+	}
+	for range chunks {
+	}
+	if err = <-errs; err != nil {
+		t.Fatalf("stream error: %v", err)
+	}
+
+	if _, err = client.ListModels(context.Background()); err == nil {
+		t.Fatal("expected ListModels to fail")
+	}
+
+	if len(tracer.spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(tracer.spans))
+	}
+
+	var span = tracer.spans[0]
+	if span.name != "chat gpt-4o" || !span.ended || span.err != nil {
+		t.Errorf("unexpected span: %+v", span)
+	}
+	for k, v := range map[string]any{
+		AttrSystem:               "openai",
+		AttrOperationName:        "chat",
+		AttrRequestModel:         "gpt-4o",
+		AttrRequestMaxTokens:     100,
+		AttrRequestTemperature:   0.5,
+		AttrResponseID:           "1",
+		AttrResponseModel:        "gpt-4o-2024-08-06",
+		AttrResponseFinishReason: []string{"stop"},
+		AttrUsageInputTokens:     8,
+		AttrUsageOutputTokens:    2,
+		AttrServerAddress:        "127.0.0.1",
+		AttrRequestID:            "req_123",
+	} {
+		if !reflect.DeepEqual(span.attrs[k], v) {
+			t.Errorf("expected %s to be %v, got %v", k, v, span.attrs[k])
+		}
+	}
+
+	span = tracer.spans[1]
+	if !span.ended || !reflect.DeepEqual(span.events, []string{EventFirstToken}) {
+		t.Errorf("unexpected streaming span: %+v", span)
+	}
+	if _, ok := span.eventAttrs[AttrTimeToFirstToken].(float64); !ok {
+		t.Errorf("expected time to first token to be recorded, got %v", span.eventAttrs[AttrTimeToFirstToken])
+	}
+	if _, ok := span.attrs[AttrTimeToFirstToken]; ok {
+		t.Error("expected time to first token not to be a span attribute")
+	}
+	if got := span.attrs[AttrResponseFinishReason]; !reflect.DeepEqual(got, []string{"stop", "length"}) {
+		t.Errorf("expected finish reasons ordered by choice, got %v", got)
+	}
+	if got := span.attrs[AttrUsageOutputTokens]; got != 4 {
+		t.Errorf("expected 4 output tokens, got %v", got)
+	}
+
+	span = tracer.spans[2]
+	if span.name != "models" || !span.ended || span.err == nil || span.attrs[AttrErrorType] != "invalid_request_error" {
+		t.Errorf("unexpected failed span: %+v", span)
+	}
+}
+
+func TestTracerNoResult(t *testing.T) {
+	var empty = func(Handler) Handler {
+		return func(context.Context, *Operation) (*OperationResult, error) { return nil, nil }
+	}
+
+	var tracer = &testTracer{}
+	var client = NewClient(testToken, WithTracer(tracer), WithMiddleware(empty))
+
+	if _, err := client.ListModels(context.Background()); !errors.Is(err, errNoResult) {
+		t.Fatalf("expected errNoResult, got %v", err)
+	}
+	if len(tracer.spans) != 1 || !tracer.spans[0].ended || !errors.Is(tracer.spans[0].err, errNoResult) {
+		t.Errorf("expected the span to end with errNoResult, got %+v", tracer.spans)
+	}
+}
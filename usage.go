@@ -9,4 +9,12 @@ type Usage struct {
 	CompletionTokens int `json:"completion_tokens,omitempty"`
 	// Total tokens is the sum of PromptTokens and CompletionTokens.
 	TotalTokens int `json:"total_tokens"`
+	// PromptTokensDetails breaks down PromptTokens. Only set for chat completions.
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the tokens in a request's prompt.
+type PromptTokensDetails struct {
+	// CachedTokens is the number of prompt tokens which were read from the prompt cache, and are billed at a discount.
+	CachedTokens int `json:"cached_tokens"`
 }
@@ -0,0 +1,488 @@
+package openai
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fabiustech/openai/images"
+	"github.com/fabiustech/openai/models"
+)
+
+// FieldError describes a single invalid field of a request.
+type FieldError struct {
+	// Field is the path to the invalid field, using the field's JSON name, e.g. "messages[2].role" or "temperature".
+	Field string
+	// Message describes why the field is invalid.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError is returned by the Validate methods of request types, and by Client methods when request validation
+// is enabled (see WithRequestValidation), if a request is invalid. It lists every invalid field.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	var msgs = make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+
+	return "openai: invalid request: " + strings.Join(msgs, "; ")
+}
+
+// validator is implemented by all request types.
+type validator interface {
+	Validate() error
+}
+
+// validate validates |v| if request validation is enabled and |v| is a request type.
+func (c *Client) validate(v any) error {
+	if !c.validateRequests {
+		return nil
+	}
+	if r, ok := v.(validator); ok {
+		return r.Validate()
+	}
+
+	return nil
+}
+
+// fieldErrors accumulates the FieldErrors of a request.
+type fieldErrors []*FieldError
+
+func (fe *fieldErrors) add(field, format string, args ...any) {
+	*fe = append(*fe, &FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err returns a *ValidationError if any FieldErrors have been added, or nil.
+func (fe fieldErrors) err() error {
+	if len(fe) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: fe}
+}
+
+func (fe *fieldErrors) between(field string, v *float64, min, max float64) {
+	if v != nil && (*v < min || *v > max) {
+		fe.add(field, "must be between %v and %v, got %v", min, max, *v)
+	}
+}
+
+func (fe *fieldErrors) penalty(field string, v float32) {
+	if v < -2 || v > 2 {
+		fe.add(field, "must be between -2 and 2, got %v", v)
+	}
+}
+
+func (fe *fieldErrors) nonNegative(field string, v int) {
+	if v < 0 {
+		fe.add(field, "must not be negative, got %d", v)
+	}
+}
+
+func (fe *fieldErrors) stop(stop []string) {
+	if len(stop) > 4 {
+		fe.add("stop", "must contain at most 4 sequences, got %d", len(stop))
+	}
+}
+
+func (fe *fieldErrors) logitBias(bias map[string]int) {
+	for k, v := range bias {
+		if v < -100 || v > 100 {
+			fe.add(fmt.Sprintf("logit_bias[%q]", k), "must be between -100 and 100, got %d", v)
+		}
+	}
+}
+
+// known checks that the enum value |v| of a required field has a string representation, as it would otherwise be
+// sent as "".
+func (fe *fieldErrors) known(field string, v fmt.Stringer) {
+	if v.String() == "" {
+		fe.add(field, "unknown value")
+	}
+}
+
+var functionName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// Validate checks the request for invalid values and combinations of values, including fields unsupported by Model
+// (as described by its models.Capabilities). It returns a *ValidationError listing every invalid field, or nil.
+func (cr *ChatCompletionRequest) Validate() error {
+	return cr.validate(false)
+}
+
+// Validate implements validator, additionally checking that the model supports streaming.
+func (s *streamingChatCompletion) Validate() error {
+	return s.ChatCompletionRequest.validate(true)
+}
+
+func (cr *ChatCompletionRequest) validate(stream bool) error {
+	var fe fieldErrors
+
+	fe.known("model", cr.Model)
+	var caps, known = cr.Model.Capabilities()
+
+	if len(cr.Messages) == 0 {
+		fe.add("messages", "must not be empty")
+	}
+	for i, m := range cr.Messages {
+		var field = fmt.Sprintf("messages[%d]", i)
+		if m == nil {
+			fe.add(field, "must not be null")
+			continue
+		}
+		switch m.Role {
+		case System:
+			if known && !caps.SystemRole {
+				fe.add(field+".role", "system messages are not supported by %s", cr.Model)
+			}
+		case User, Assistant:
+		case RoleFunction:
+			if m.Name == "" {
+				fe.add(field+".name", "is required for function messages")
+			}
+		case RoleTool:
+			if m.ToolCallID == "" {
+				fe.add(field+".tool_call_id", "is required for tool messages")
+			}
+		default:
+			fe.add(field+".role", "unknown role %q", m.Role)
+		}
+
+		for j, p := range m.ContentParts {
+			if p == nil {
+				fe.add(fmt.Sprintf("%s.content[%d]", field, j), "must not be null")
+				continue
+			}
+			if p.Type == ContentPartTypeImageURL && known && !caps.Vision {
+				fe.add(fmt.Sprintf("%s.content[%d]", field, j), "image inputs are not supported by %s", cr.Model)
+			}
+		}
+	}
+
+	for i, f := range cr.Functions {
+		fe.function(fmt.Sprintf("functions[%d]", i), f)
+	}
+	if len(cr.Functions) > 0 && known && !caps.Functions {
+		fe.add("functions", "function calling is not supported by %s", cr.Model)
+	}
+	if cr.FunctionCall != nil && len(cr.Functions) == 0 {
+		fe.add("function_call", "is only allowed when functions are specified")
+	}
+
+	for i, t := range cr.Tools {
+		var field = fmt.Sprintf("tools[%d]", i)
+		if t == nil {
+			fe.add(field, "must not be null")
+			continue
+		}
+		if t.Type != ToolTypeFunction || t.Function == nil {
+			fe.add(field, "must be a function tool")
+			continue
+		}
+		fe.function(field+".function", t.Function)
+	}
+	if len(cr.Tools) > 0 && known && !caps.Tools {
+		fe.add("tools", "tools are not supported by %s", cr.Model)
+	}
+	if cr.ToolChoice != nil && len(cr.Tools) == 0 {
+		fe.add("tool_choice", "is only allowed when tools are specified")
+	}
+	if cr.ParallelToolCalls != nil && len(cr.Tools) == 0 {
+		fe.add("parallel_tool_calls", "is only allowed when tools are specified")
+	}
+
+	fe.between("temperature", cr.Temperature, 0, 2)
+	fe.between("top_p", cr.TopP, 0, 1)
+	fe.penalty("presence_penalty", cr.PresencePenalty)
+	fe.penalty("frequency_penalty", cr.FrequencyPenalty)
+	fe.nonNegative("n", cr.N)
+	fe.nonNegative("max_tokens", cr.MaxTokens)
+	fe.stop(cr.Stop)
+	fe.logitBias(cr.LogitBias)
+
+	if known && caps.Reasoning {
+		if cr.Temperature != nil && *cr.Temperature != 1 {
+			fe.add("temperature", "is not supported by %s", cr.Model)
+		}
+		if cr.TopP != nil && *cr.TopP != 1 {
+			fe.add("top_p", "is not supported by %s", cr.Model)
+		}
+		if cr.PresencePenalty != 0 {
+			fe.add("presence_penalty", "is not supported by %s", cr.Model)
+		}
+		if cr.FrequencyPenalty != 0 {
+			fe.add("frequency_penalty", "is not supported by %s", cr.Model)
+		}
+		if cr.MaxTokens != 0 {
+			fe.add("max_tokens", "is not supported by %s", cr.Model)
+		}
+	}
+	if known && caps.MaxOutputTokens > 0 && cr.MaxTokens > caps.MaxOutputTokens {
+		fe.add("max_tokens", "must be at most %d for %s, got %d", caps.MaxOutputTokens, cr.Model, cr.MaxTokens)
+	}
+
+	if rf := cr.ResponseFormat; rf != nil {
+		switch rf.Type {
+		case ResponseFormatTypeText:
+		case ResponseFormatTypeJSONObject:
+			if known && !caps.JSONMode {
+				fe.add("response_format", "JSON mode is not supported by %s", cr.Model)
+			}
+		case ResponseFormatTypeJSONSchema:
+			if rf.JSONSchema == nil {
+				fe.add("response_format.json_schema", "is required for type %q", rf.Type)
+			} else if !functionName.MatchString(rf.JSONSchema.Name) {
+				fe.add("response_format.json_schema.name", "must match %s", functionName)
+			}
+			if known && !caps.StructuredOutputs {
+				fe.add("response_format", "structured outputs are not supported by %s", cr.Model)
+			}
+		default:
+			fe.add("response_format.type", "unknown type %q", rf.Type)
+		}
+	}
+
+	if stream {
+		if known && !caps.Streaming {
+			fe.add("stream", "streaming is not supported by %s", cr.Model)
+		}
+	} else if cr.StreamOptions != nil {
+		fe.add("stream_options", "is only allowed when streaming")
+	}
+
+	return fe.err()
+}
+
+func (fe *fieldErrors) function(field string, f *Function) {
+	if f == nil {
+		fe.add(field, "must not be null")
+		return
+	}
+	if !functionName.MatchString(f.Name) {
+		fe.add(field+".name", "must be 1-64 characters of a-z, A-Z, 0-9, underscores and dashes, got %q", f.Name)
+	}
+}
+
+// Validate checks the request for invalid values and combinations of values. It returns a *ValidationError listing
+// every invalid field, or nil.
+func (cr *CompletionRequest[T]) Validate() error {
+	return cr.validate(false)
+}
+
+// Validate implements validator, additionally checking fields which are incompatible with streaming.
+func (s *streamingCompletion) Validate() error {
+	return s.CompletionRequest.validate(true)
+}
+
+func (cr *CompletionRequest[T]) validate(stream bool) error {
+	var fe fieldErrors
+
+	switch m := any(cr.Model).(type) {
+	case models.Completion:
+		fe.known("model", m)
+		if caps, ok := m.Capabilities(); ok && caps.ContextWindow > 0 && cr.MaxTokens > caps.ContextWindow {
+			fe.add("max_tokens", "must be at most %d for %s, got %d", caps.ContextWindow, m, cr.MaxTokens)
+		}
+	case models.FineTunedModel:
+		if m == "" {
+			fe.add("model", "must not be empty")
+		}
+	}
+
+	fe.between("temperature", cr.Temperature, 0, 2)
+	fe.between("top_p", cr.TopP, 0, 1)
+	fe.penalty("presence_penalty", cr.PresencePenalty)
+	fe.penalty("frequency_penalty", cr.FrequencyPenalty)
+	fe.nonNegative("n", cr.N)
+	fe.nonNegative("max_tokens", cr.MaxTokens)
+	fe.nonNegative("best_of", cr.BestOf)
+	fe.stop(cr.Stop)
+	fe.logitBias(cr.LogitBias)
+
+	if cr.LogProbs != nil && (*cr.LogProbs < 0 || *cr.LogProbs > 5) {
+		fe.add("logprobs", "must be between 0 and 5, got %d", *cr.LogProbs)
+	}
+
+	if cr.BestOf > 1 || cr.N > 1 {
+		var n = cr.N
+		if n == 0 {
+			n = 1
+		}
+		if cr.BestOf != 0 && cr.BestOf <= n {
+			fe.add("best_of", "must be greater than n (%d), got %d", n, cr.BestOf)
+		}
+	}
+	if stream && cr.BestOf > 1 {
+		fe.add("best_of", "cannot be used when streaming")
+	}
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (er *EditsRequest) Validate() error {
+	var fe fieldErrors
+
+	fe.known("model", er.Model)
+	if er.Instruction == "" {
+		fe.add("instruction", "must not be empty")
+	}
+	fe.between("temperature", er.Temperature, 0, 2)
+	fe.between("top_p", er.TopP, 0, 1)
+	fe.nonNegative("n", er.N)
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (er *EmbeddingRequest) Validate() error {
+	var fe fieldErrors
+
+	fe.known("model", er.Model)
+	if len(er.Input) == 0 {
+		fe.add("input", "must not be empty")
+	}
+	if len(er.Input) > 2048 {
+		fe.add("input", "must contain at most 2048 inputs, got %d", len(er.Input))
+	}
+	for i, in := range er.Input {
+		if in == "" {
+			fe.add(fmt.Sprintf("input[%d]", i), "must not be empty")
+		}
+	}
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (mr *ModerationRequest) Validate() error {
+	var fe fieldErrors
+
+	if mr.Model != models.UnknownModeration {
+		fe.known("model", mr.Model)
+	}
+	if mr.Input == "" {
+		fe.add("input", "must not be empty")
+	}
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (ar *AudioTranscriptionRequest) Validate() error {
+	var fe fieldErrors
+
+	if ar.File == nil {
+		fe.add("file", "must not be null")
+	}
+	fe.known("model", ar.Model)
+	if ar.ResponseFormat != nil {
+		fe.known("response_format", ar.ResponseFormat)
+	}
+	fe.between("temperature", ar.Temperature, 0, 1)
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (fr *FileRequest) Validate() error {
+	var fe fieldErrors
+
+	if fr.File == nil {
+		fe.add("file", "must not be null")
+	}
+	if fr.Purpose == "" {
+		fe.add("purpose", "must not be empty")
+	}
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values and combinations of values. It returns a *ValidationError listing
+// every invalid field, or nil.
+func (fr *FineTuneRequest) Validate() error {
+	var fe fieldErrors
+
+	if fr.TrainingFile == "" {
+		fe.add("training_file", "must not be empty")
+	}
+	if fr.Model != nil {
+		fe.known("model", fr.Model)
+	}
+	if n := utf8.RuneCountInString(fr.Suffix); n > 40 {
+		fe.add("suffix", "must be at most 40 characters, got %d", n)
+	}
+	if fr.ComputeClassificationMetrics && fr.ValidationFile == nil {
+		fe.add("validation_file", "is required to compute classification metrics")
+	}
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (ir *CreateImageRequest) Validate() error {
+	var fe fieldErrors
+
+	fe.prompt(ir.Prompt)
+	fe.images(ir.N, ir.Size, ir.ResponseFormat)
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (eir *EditImageRequest) Validate() error {
+	var fe fieldErrors
+
+	if eir.Image == "" {
+		fe.add("image", "must not be empty")
+	}
+	fe.prompt(eir.Prompt)
+	fe.images(eir.N, eir.Size, eir.ResponseFormat)
+
+	return fe.err()
+}
+
+// Validate checks the request for invalid values. It returns a *ValidationError listing every invalid field, or nil.
+func (vir *VariationImageRequest) Validate() error {
+	var fe fieldErrors
+
+	if vir.Image == "" {
+		fe.add("image", "must not be empty")
+	}
+	fe.images(vir.N, vir.Size, vir.ResponseFormat)
+
+	return fe.err()
+}
+
+func (fe *fieldErrors) prompt(prompt string) {
+	if prompt == "" {
+		fe.add("prompt", "must not be empty")
+	}
+	if n := utf8.RuneCountInString(prompt); n > 1000 {
+		fe.add("prompt", "must be at most 1000 characters, got %d", n)
+	}
+}
+
+// images checks the fields common to all image requests. The zero values of |size| and |format| are omitted from
+// requests, and so are valid.
+func (fe *fieldErrors) images(n int, size images.Size, format images.Format) {
+	if n < 0 || n > 10 {
+		fe.add("n", "must be between 1 and 10, got %d", n)
+	}
+	if size != images.SizeInvalid {
+		fe.known("size", size)
+	}
+	if format != images.FormatInvalid {
+		fe.known("response_format", format)
+	}
+}
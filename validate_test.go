@@ -0,0 +1,242 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/fabiustech/openai/images"
+	"github.com/fabiustech/openai/models"
+	"github.com/fabiustech/openai/params"
+)
+
+// invalidFields returns the sorted fields of the *ValidationError |err|, or nil if |err| is nil.
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected *ValidationError, got %T: %v", err, err)
+	}
+
+	var fields []string
+	for _, fe := range ve.Errors {
+		fields = append(fields, fe.Field)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestChatCompletionRequestValidate(t *testing.T) {
+	var user = []*ChatMessage{{Role: User, Content: "hi"}}
+	var fn = &Function{Name: "get_weather"}
+
+	var tcs = []struct {
+		name string
+		req  *ChatCompletionRequest
+		want []string
+	}{
+		{
+			name: "valid",
+			req:  &ChatCompletionRequest{Model: models.GPT4o, Messages: user, Tools: []*Tool{NewFunctionTool(fn)}},
+		},
+		{
+			name: "unknown model",
+			req:  &ChatCompletionRequest{Model: models.UnknownChatCompletion, Messages: user},
+			want: []string{"model"},
+		},
+		{
+			name: "ranges",
+			req: &ChatCompletionRequest{
+				Model:       models.GPT4,
+				Messages:    user,
+				Temperature: params.Optional(2.5),
+				TopP:        params.Optional(-1.0),
+				Stop:        []string{"a", "b", "c", "d", "e"},
+				LogitBias:   map[string]int{"50256": -101},
+				MaxTokens:   10000,
+			},
+			want: []string{"logit_bias[\"50256\"]", "max_tokens", "stop", "temperature", "top_p"},
+		},
+		{
+			name: "messages",
+			req: &ChatCompletionRequest{
+				Model: models.GPT4,
+				Messages: []*ChatMessage{
+					{Role: "robot"},
+					{Role: RoleTool, Content: "1"},
+					{Role: User, ContentParts: []*ContentPart{ImageURLPart("https://example.com/a.png", ImageDetailAuto)}},
+				},
+			},
+			want: []string{"messages[0].role", "messages[1].tool_call_id", "messages[2].content[0]"},
+		},
+		{
+			name: "null entries",
+			req: &ChatCompletionRequest{
+				Model:     models.GPT4o,
+				Messages:  []*ChatMessage{nil, {Role: User, ContentParts: []*ContentPart{nil}}},
+				Functions: []*Function{nil},
+				Tools:     []*Tool{nil},
+			},
+			want: []string{"functions[0]", "messages[0]", "messages[1].content[0]", "tools[0]"},
+		},
+		{
+			name: "functions unsupported",
+			req: &ChatCompletionRequest{
+				Model:     models.GPT4_0314,
+				Messages:  user,
+				Functions: []*Function{{Name: "bad name"}},
+			},
+			want: []string{"functions", "functions[0].name"},
+		},
+		{
+			name: "o1",
+			req: &ChatCompletionRequest{
+				Model:          models.O1Mini,
+				Messages:       []*ChatMessage{{Role: System, Content: "be nice"}},
+				Temperature:    params.Optional(0.5),
+				ResponseFormat: ResponseFormatJSONObject(),
+			},
+			want: []string{"messages[0].role", "response_format", "temperature"},
+		},
+		{
+			name: "without tools",
+			req: &ChatCompletionRequest{
+				Model:             models.GPT4o,
+				Messages:          user,
+				ToolChoice:        ToolChoiceAuto(),
+				ParallelToolCalls: params.Optional(false),
+				StreamOptions:     &StreamOptions{IncludeUsage: true},
+			},
+			want: []string{"parallel_tool_calls", "stream_options", "tool_choice"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := invalidFields(t, tc.req.Validate()); !equalFields(got, tc.want) {
+				t.Fatalf("expected invalid fields %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	var stream = &streamingChatCompletion{Stream: true, ChatCompletionRequest: &ChatCompletionRequest{
+		Model:    models.O1Preview,
+		Messages: user,
+	}}
+	if got := invalidFields(t, stream.Validate()); !equalFields(got, []string{"stream"}) {
+		t.Errorf("expected streaming to be invalid for o1, got %q", got)
+	}
+}
+
+func TestCompletionRequestValidate(t *testing.T) {
+	var tcs = []struct {
+		name string
+		req  *CompletionRequest[models.Completion]
+		want []string
+	}{
+		{
+			name: "valid",
+			req:  &CompletionRequest[models.Completion]{Model: models.TextDavinci003, N: 2, BestOf: 3},
+		},
+		{
+			name: "best_of",
+			req:  &CompletionRequest[models.Completion]{Model: models.TextDavinci003, N: 2, BestOf: 2},
+			want: []string{"best_of"},
+		},
+		{
+			name: "logprobs",
+			req:  &CompletionRequest[models.Completion]{Model: models.TextDavinci003, LogProbs: params.Optional(6)},
+			want: []string{"logprobs"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := invalidFields(t, tc.req.Validate()); !equalFields(got, tc.want) {
+				t.Fatalf("expected invalid fields %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	var ft = &CompletionRequest[models.FineTunedModel]{}
+	if got := invalidFields(t, ft.Validate()); !equalFields(got, []string{"model"}) {
+		t.Errorf("expected empty fine-tuned model to be invalid, got %q", got)
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	var tcs = []struct {
+		name string
+		req  validator
+		want []string
+	}{
+		{name: "edit", req: &EditsRequest{Model: models.TextDavinciEdit001}, want: []string{"instruction"}},
+		{name: "embedding", req: &EmbeddingRequest{Model: models.AdaEmbeddingV2, Input: []string{""}}, want: []string{"input[0]"}},
+		{name: "moderation", req: &ModerationRequest{Input: "hi"}},
+		{name: "image", req: &CreateImageRequest{Prompt: "a cat", N: 11, Size: images.Size(99)}, want: []string{"n", "size"}},
+		{name: "variation", req: &VariationImageRequest{}, want: []string{"image"}},
+		{name: "audio", req: &AudioTranscriptionRequest{Model: models.Whisper1}, want: []string{"file"}},
+		{name: "file", req: &FileRequest{}, want: []string{"file", "purpose"}},
+		{
+			name: "fine-tune",
+			req:  &FineTuneRequest{TrainingFile: "file-1", ComputeClassificationMetrics: true},
+			want: []string{"validation_file"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := invalidFields(t, tc.req.Validate()); !equalFields(got, tc.want) {
+				t.Fatalf("expected invalid fields %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestWithRequestValidation(t *testing.T) {
+	var sent bool
+	var hc = &http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		sent = true
+		return nil, errors.New("unexpected request")
+	})}
+
+	var c = NewClient("token", WithHTTPClient(hc), WithRequestValidation())
+
+	var _, err = c.CreateChatCompletion(context.Background(), &ChatCompletionRequest{Model: models.GPT4})
+	if got := invalidFields(t, err); !equalFields(got, []string{"messages"}) {
+		t.Errorf("expected messages to be invalid, got %q", got)
+	}
+
+	_, _, err = c.CreateStreamingCompletion(context.Background(), &CompletionRequest[models.Completion]{
+		Model:  models.TextDavinci003,
+		BestOf: 2,
+	})
+	if got := invalidFields(t, err); !equalFields(got, []string{"best_of"}) {
+		t.Errorf("expected best_of to be invalid, got %q", got)
+	}
+
+	if sent {
+		t.Error("expected invalid requests not to be sent")
+	}
+}